@@ -6,6 +6,8 @@ import (
 	"path"
 	"strings"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -22,6 +24,16 @@ const (
 
 	powerVSProvider  = "powervs"
 	ibmCloudProvider = "ibmcloud"
+
+	// The ibmcloud provider serves both the Power VS and the IBMCloud VPC platforms, and reads its credentials from
+	// the powerVSCredentialsSecretName secret of its assets. Each platform has its own CredentialsRequest, so on
+	// IBMCloud the provider is pointed to the secret of the IBMCloud VPC CredentialsRequest instead.
+	powerVSCredentialsSecretName  = "capi-ibmcloud-manager-bootstrap-credentials"
+	ibmCloudCredentialsSecretName = "capi-ibmcloud-vpc-manager-bootstrap-credentials"
+	componentsConfigMapKey        = "components"
+
+	// SourceAssetAnnotation records the embedded asset an object was read from.
+	SourceAssetAnnotation = "cluster-capi-operator.openshift.io/source-asset"
 )
 
 //go:embed core-capi/*.yaml infrastructure-providers/*.yaml
@@ -64,6 +76,11 @@ func ReadInfrastructureProviderAssets(scheme *runtime.Scheme, platformType strin
 		return nil, err
 	}
 
+	credentialsSecretName := ""
+	if platformType == ibmCloudProvider {
+		credentialsSecretName = ibmCloudCredentialsSecretName
+	}
+
 	// for Power VS the upstream cluster api provider name is ibmcloud
 	// https://github.com/kubernetes-sigs/cluster-api/blob/main/cmd/clusterctl/client/config/providers_client.go#L210-L214
 	if platformType == powerVSProvider {
@@ -94,9 +111,31 @@ func ReadInfrastructureProviderAssets(scheme *runtime.Scheme, platformType strin
 		return nil, fmt.Errorf("expected exactly 2 assets for infrastructure provider, got %d", len(objs))
 	}
 
+	if credentialsSecretName != "" {
+		if err := setCredentialsSecretName(objs[InfrastructureProviderConfigMapKey], credentialsSecretName); err != nil {
+			return nil, err
+		}
+	}
+
 	return objs, nil
 }
 
+// setCredentialsSecretName replaces the credentials secret mounted by the ibmcloud provider in its components.
+func setCredentialsSecretName(obj client.Object, name string) error {
+	cm, ok := obj.(*corev1.ConfigMap)
+	if !ok {
+		return fmt.Errorf("expected a ConfigMap, got %T", obj)
+	}
+
+	secretRef := fmt.Sprintf("secretName: %s\n", powerVSCredentialsSecretName)
+	if strings.Count(cm.Data[componentsConfigMapKey], secretRef) != 1 {
+		return fmt.Errorf("expected the components of ConfigMap %s to mount the secret %s once", cm.Name, powerVSCredentialsSecretName)
+	}
+	cm.Data[componentsConfigMapKey] = strings.Replace(cm.Data[componentsConfigMapKey], secretRef, fmt.Sprintf("secretName: %s\n", name), 1)
+
+	return nil
+}
+
 func readObject(dir, name string, scheme *runtime.Scheme) (runtime.Object, error) {
 	b, err := fs.ReadFile(path.Join(dir, name))
 	if err != nil {
@@ -107,5 +146,17 @@ func readObject(dir, name string, scheme *runtime.Scheme) (runtime.Object, error
 	if err != nil {
 		return nil, err
 	}
+
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, err
+	}
+	annotations := accessor.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[SourceAssetAnnotation] = path.Join(dir, name)
+	accessor.SetAnnotations(annotations)
+
 	return obj, nil
 }
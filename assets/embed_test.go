@@ -3,6 +3,7 @@ package assets
 import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/kubernetes/scheme"
 	operatorv1 "sigs.k8s.io/cluster-api-operator/api/v1alpha1"
@@ -27,6 +28,8 @@ var _ = Describe("Read assets suite", func() {
 		Expect(objs).Should(HaveKey(CoreProviderConfigMapKey))
 		Expect(objs[CoreProviderConfigMapKey]).ToNot(BeNil())
 		Expect(objs[CoreProviderConfigMapKey].GetObjectKind().GroupVersionKind().Kind).To(Equal("ConfigMap"))
+
+		Expect(objs[CoreProviderKey].GetAnnotations()).To(HaveKeyWithValue(SourceAssetAnnotation, "core-capi/core-cluster-api-provider.yaml"))
 	})
 
 	It("should read infra provider assets", func() {
@@ -43,4 +46,32 @@ var _ = Describe("Read assets suite", func() {
 		Expect(objs[InfrastructureProviderConfigMapKey]).ToNot(BeNil())
 		Expect(objs[InfrastructureProviderConfigMapKey].GetObjectKind().GroupVersionKind().Kind).To(Equal("ConfigMap"))
 	})
+
+	It("should read the ibmcloud provider assets for the IBMCloud and Power VS platforms", func() {
+		for _, platformType := range []string{"ibmcloud", "powervs"} {
+			objs, err := ReadInfrastructureProviderAssets(scheme.Scheme, platformType)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(objs).To(HaveLen(2))
+			Expect(objs[InfrastructureProviderKey].GetName()).To(Equal("ibmcloud"))
+		}
+	})
+
+	It("should mount the credentials secret of the CredentialsRequest of the platform", func() {
+		for platformType, secretName := range map[string]string{
+			"powervs":  powerVSCredentialsSecretName,
+			"ibmcloud": ibmCloudCredentialsSecretName,
+		} {
+			objs, err := ReadInfrastructureProviderAssets(scheme.Scheme, platformType)
+			Expect(err).NotTo(HaveOccurred())
+
+			components := objs[InfrastructureProviderConfigMapKey].(*corev1.ConfigMap).Data[componentsConfigMapKey]
+			Expect(components).To(ContainSubstring("secretName: " + secretName + "\n"))
+			for _, other := range []string{powerVSCredentialsSecretName, ibmCloudCredentialsSecretName} {
+				if other != secretName {
+					Expect(components).NotTo(ContainSubstring(other))
+				}
+			}
+		}
+	})
 })
@@ -4,6 +4,8 @@ import (
 	"context"
 	"flag"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/pflag"
@@ -11,21 +13,24 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
 	"k8s.io/component-base/config"
 	"k8s.io/component-base/config/options"
 	"k8s.io/klog/v2"
-	"k8s.io/klog/v2/klogr"
 	operatorv1 "sigs.k8s.io/cluster-api-operator/api/v1alpha1"
 	awsv1 "sigs.k8s.io/cluster-api-provider-aws/v2/api/v1beta1"
 	azurev1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 	gcpv1 "sigs.k8s.io/cluster-api-provider-gcp/api/v1beta1"
-	ibmpowervsv1 "sigs.k8s.io/cluster-api-provider-ibmcloud/api/v1beta1"
+	ibmcloudv1 "sigs.k8s.io/cluster-api-provider-ibmcloud/api/v1beta1"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 
 	configv1 "github.com/openshift/api/config/v1"
 	"github.com/openshift/cluster-capi-operator/pkg/controllers"
@@ -45,6 +50,7 @@ var (
 		LeaseDuration:     util.LeaseDuration,
 		RenewDeadline:     util.RenewDeadline,
 		RetryPeriod:       util.RetryPeriod,
+		ResourceLock:      resourcelock.LeasesResourceLock,
 		ResourceName:      "cluster-capi-operator-leader",
 		ResourceNamespace: "openshift-cluster-api",
 	}
@@ -58,6 +64,31 @@ var (
 		":9440",
 		"The address for health checking.",
 	)
+	loggingFormat = flag.String(
+		"logging-format",
+		util.LoggingFormatText,
+		"The format of the logs, either text or json.",
+	)
+	profilingAddr = flag.String(
+		"profiling-addr",
+		"",
+		"The address the pprof and expvar endpoints bind to, e.g. 127.0.0.1:6060. Profiling is disabled when empty.",
+	)
+	kubeAPIQPS = flag.Float64(
+		"kube-api-qps",
+		defaultKubeAPIQPS,
+		"The maximum sustained queries per second from the operator to the API server.",
+	)
+	kubeAPIBurst = flag.Int(
+		"kube-api-burst",
+		defaultKubeAPIBurst,
+		"The maximum burst of queries from the operator to the API server.",
+	)
+	informerStalenessWindow = flag.Duration(
+		"informer-staleness-window",
+		30*time.Minute,
+		"The time after which the health check fails when a watched informer has not delivered an event. Must be longer than the 10 minutes sync period. The check is disabled when 0.",
+	)
 	managedNamespace = flag.String(
 		"namespace",
 		controllers.DefaultManagedNamespace,
@@ -83,13 +114,40 @@ var (
 		"/tmp/k8s-webhook-server/serving-certs/",
 		"Webhook cert dir, only used when webhook-port is specified.",
 	)
+	kubeconfigTokenExpiration = flag.Duration(
+		"kubeconfig-token-expiration",
+		kubeconfig.DefaultTokenExpiration,
+		"The lifetime requested for the token of the kubeconfig used by CAPI controllers. Must be at least 10 minutes.",
+	)
+	kubeconfigTokenRotationFraction = flag.Float64(
+		"kubeconfig-token-rotation-fraction",
+		kubeconfig.DefaultTokenRotationFraction,
+		"The fraction of the kubeconfig token lifetime after which the token is rotated. Must be greater than 0 and less than 1.",
+	)
+	kubeconfigTokenAudiences = flag.String(
+		"kubeconfig-token-audiences",
+		"",
+		"Comma separated audiences requested for the token of the kubeconfig used by CAPI controllers. The API server audience is used when empty.",
+	)
+	kubeconfigCAConfigMap = flag.String(
+		"kubeconfig-ca-configmap",
+		"",
+		"The namespace/name of the ConfigMap whose ca.crt is embedded in the kubeconfig used by CAPI controllers. The kube-root-ca.crt ConfigMap of the managed namespace is used when empty.",
+	)
 )
 
+// releaseVersion is resolved once at startup and reported in the ClusterOperator versions.
+var releaseVersion string
+
 const (
-	defaultImagesLocation         = "./dev-images.json"
-	defaultProvidersLocation      = "./providers-list.yaml"
-	releaseVersionEnvVariableName = "RELEASE_VERSION"
-	unknownVersionValue           = "unknown"
+	defaultImagesLocation    = "./dev-images.json"
+	defaultProvidersLocation = "./providers-list.yaml"
+	unknownVersionValue      = "unknown"
+	// operatorServiceAccountName is the service account of the operator Deployment.
+	operatorServiceAccountName = "cluster-capi-operator"
+	// The controller-runtime defaults of 20 QPS and 30 burst throttle the controllers while the providers are installed.
+	defaultKubeAPIQPS   = 50
+	defaultKubeAPIBurst = 100
 )
 
 func init() {
@@ -102,43 +160,92 @@ func init() {
 	utilruntime.Must(gcpv1.AddToScheme(scheme))
 	utilruntime.Must(clusterv1.AddToScheme(scheme))
 	utilruntime.Must(clusterctlv1.AddToScheme(scheme))
-	utilruntime.Must(ibmpowervsv1.AddToScheme(scheme))
+	utilruntime.Must(ibmcloudv1.AddToScheme(scheme))
 	// +kubebuilder:scaffold:scheme
 }
 
 func main() {
 	klog.InitFlags(nil)
 
-	ctrl.SetLogger(klogr.New())
-
 	// Once all the flags are regitered, switch to pflag
 	// to allow leader lection flags to be bound
 	pflag.CommandLine.AddGoFlagSet(flag.CommandLine)
 	options.BindLeaderElectionFlags(&leaderElectionConfig, pflag.CommandLine)
 	pflag.Parse()
 
+	logger, err := util.NewLogger(*loggingFormat, klogVerbosity(), os.Stderr)
+	if err != nil {
+		klog.Error(err, "unable to set up logging")
+		os.Exit(1)
+	}
+	ctrl.SetLogger(logger)
+	if *loggingFormat == util.LoggingFormatJSON {
+		// Route klog through the same sink, so library messages are not logged in a different format.
+		klog.SetLogger(logger)
+	}
+
+	if *profilingAddr != "" && (*profilingAddr == *metricsAddr || *profilingAddr == *healthAddr) {
+		klog.Errorf("profiling address %s must not be shared with the metrics or health probe address", *profilingAddr)
+		os.Exit(1)
+	}
+	if *kubeconfigTokenExpiration < kubeconfig.MinTokenExpiration {
+		klog.Errorf("kubeconfig token expiration %v is shorter than the minimum of %v", *kubeconfigTokenExpiration, kubeconfig.MinTokenExpiration)
+		os.Exit(1)
+	}
+	if *kubeconfigTokenRotationFraction <= 0 || *kubeconfigTokenRotationFraction >= 1 {
+		klog.Errorf("kubeconfig token rotation fraction %v must be greater than 0 and less than 1", *kubeconfigTokenRotationFraction)
+		os.Exit(1)
+	}
+
+	if *kubeAPIQPS <= 0 || *kubeAPIBurst <= 0 {
+		klog.Errorf("kube API QPS %v and burst %d must be greater than 0", *kubeAPIQPS, *kubeAPIBurst)
+		os.Exit(1)
+	}
+
+	var caConfigMap client.ObjectKey
+	if *kubeconfigCAConfigMap != "" {
+		ref, err := kubeconfig.ParseConfigMapReference(*kubeconfigCAConfigMap)
+		if err != nil {
+			klog.Error(err, "unable to parse kubeconfig CA ConfigMap reference")
+			os.Exit(1)
+		}
+		caConfigMap = ref
+	}
+
 	syncPeriod := 10 * time.Minute
+	if *informerStalenessWindow != 0 && *informerStalenessWindow <= syncPeriod {
+		klog.Errorf("informer staleness window %v must be longer than the sync period of %v", *informerStalenessWindow, syncPeriod)
+		os.Exit(1)
+	}
 
-	cacheBuilder := cache.MultiNamespacedCacheBuilder([]string{
-		*managedNamespace, secretsync.SecretSourceNamespace,
-	})
-
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
-		Namespace:               *managedNamespace,
-		Scheme:                  scheme,
-		SyncPeriod:              &syncPeriod,
-		MetricsBindAddress:      *metricsAddr,
-		HealthProbeBindAddress:  *healthAddr,
-		LeaderElectionNamespace: leaderElectionConfig.ResourceNamespace,
-		LeaderElection:          leaderElectionConfig.LeaderElect,
-		LeaseDuration:           &leaderElectionConfig.LeaseDuration.Duration,
-		LeaderElectionID:        leaderElectionConfig.ResourceName,
-		RetryPeriod:             &leaderElectionConfig.RetryPeriod.Duration,
-		RenewDeadline:           &leaderElectionConfig.RenewDeadline.Duration,
-		NewCache:                cacheBuilder,
-		Port:                    *webhookPort,
-		CertDir:                 *webhookCertDir,
-	})
+	cacheNamespaces := []string{*managedNamespace, secretsync.SecretSourceNamespace}
+	if caConfigMap.Namespace != "" && caConfigMap.Namespace != *managedNamespace && caConfigMap.Namespace != secretsync.SecretSourceNamespace {
+		cacheNamespaces = append(cacheNamespaces, caConfigMap.Namespace)
+	}
+	cacheBuilder := util.WithoutManagedFields(util.WithLabeledPods(cache.MultiNamespacedCacheBuilder(cacheNamespaces), util.ProviderLabel))
+
+	mgrOptions := ctrl.Options{
+		Namespace:              *managedNamespace,
+		Scheme:                 scheme,
+		SyncPeriod:             &syncPeriod,
+		MetricsBindAddress:     *metricsAddr,
+		HealthProbeBindAddress: *healthAddr,
+		NewCache:               cacheBuilder,
+		Port:                   *webhookPort,
+		CertDir:                *webhookCertDir,
+	}
+	util.SetLeaderElectionOptions(&mgrOptions, leaderElectionConfig)
+
+	restConfig := ctrl.GetConfigOrDie()
+	restConfig.QPS = float32(*kubeAPIQPS)
+	restConfig.Burst = *kubeAPIBurst
+
+	// The provider has to be set before the manager creates the leader elector.
+	leaderElectionStatus := util.NewLeaderElectionStatus()
+	leaderelection.SetProvider(leaderElectionStatus)
+	ctrlmetrics.Registry.MustRegister(leaderElectionStatus)
+
+	mgr, err := ctrl.NewManager(restConfig, mgrOptions)
 	if err != nil {
 		klog.Error(err, "unable to start manager")
 		os.Exit(1)
@@ -150,19 +257,32 @@ func main() {
 		os.Exit(1)
 	}
 
+	imageOverrides := util.ReadImageOverrides(os.Environ())
+	for name, image := range imageOverrides {
+		klog.Infof("overriding image for provider %q with %q", name, image)
+	}
+
 	supportedProviders, err := util.ReadProvidersFile(*providerFile)
 	if err != nil {
 		klog.Error(err, "unable to get providers from file", "name", *providerFile)
 		os.Exit(1)
 	}
 
+	// The cache is not started yet, so the release version is resolved with a live read.
+	releaseVersion = util.GetReleaseVersion(context.Background(), mgr.GetAPIReader(), unknownVersionValue)
+
 	platform, err := util.GetPlatform(context.Background(), mgr.GetAPIReader())
 	if err != nil {
 		klog.Error(err, "unable to get platform from infrastructure object")
 		os.Exit(1)
 	}
 
-	setupReconcilers(mgr, platform, containerImages, supportedProviders)
+	if err := util.AddProfilingServer(mgr, *profilingAddr); err != nil {
+		klog.Error(err, "unable to set up profiling server")
+		os.Exit(1)
+	}
+
+	setupReconcilers(mgr, platform, containerImages, imageOverrides, supportedProviders, caConfigMap)
 	setupWebhooks(mgr, platform)
 
 	// +kubebuilder:scaffold:builder
@@ -171,10 +291,30 @@ func main() {
 		klog.Error(err, "unable to set up health check")
 		os.Exit(1)
 	}
+	if *informerStalenessWindow != 0 {
+		// The Infrastructure and the ClusterOperator always exist, so their informers deliver an event every sync period.
+		watchdog := util.NewInformerWatchdog(*informerStalenessWindow)
+		if err := watchdog.Watch(context.Background(), mgr.GetCache(), &configv1.Infrastructure{}, &configv1.ClusterOperator{}); err != nil {
+			klog.Error(err, "unable to set up informer watchdog")
+			os.Exit(1)
+		}
+		if err := mgr.AddHealthzCheck("informer-watchdog", watchdog.Check); err != nil {
+			klog.Error(err, "unable to set up informer watchdog health check")
+			os.Exit(1)
+		}
+	}
+	if err := mgr.AddHealthzCheck("leader-election", leaderElectionStatus.Check); err != nil {
+		klog.Error(err, "unable to set up leader election health check")
+		os.Exit(1)
+	}
 	if err := mgr.AddReadyzCheck("check", healthz.Ping); err != nil {
 		klog.Error(err, "unable to set up ready check")
 		os.Exit(1)
 	}
+	if err := mgr.AddReadyzCheck("cache-sync", util.NewCacheSyncCheck(mgr.GetCache())); err != nil {
+		klog.Error(err, "unable to set up cache sync ready check")
+		os.Exit(1)
+	}
 
 	klog.Info("starting manager")
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
@@ -183,29 +323,42 @@ func main() {
 	}
 }
 
-func getReleaseVersion() string {
-	releaseVersion := os.Getenv(releaseVersionEnvVariableName)
-	if len(releaseVersion) == 0 {
-		releaseVersion = unknownVersionValue
-		klog.Infof("%s environment variable is missing, defaulting to %q", releaseVersionEnvVariableName, unknownVersionValue)
+// klogVerbosity returns the verbosity set with the klog -v flag.
+func klogVerbosity() int {
+	verbosity, err := strconv.Atoi(flag.Lookup("v").Value.String())
+	if err != nil {
+		return 0
+	}
+	return verbosity
+}
+
+// splitList splits a comma separated flag value, dropping empty items.
+func splitList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
 	}
-	return releaseVersion
+	return items
 }
 
-func getClusterOperatorStatusClient(mgr manager.Manager, controller string) operatorstatus.ClusterOperatorStatusClient {
+func getClusterOperatorStatusClient(mgr manager.Manager, platform configv1.PlatformType, controller string) operatorstatus.ClusterOperatorStatusClient {
 	return operatorstatus.ClusterOperatorStatusClient{
 		Client:           mgr.GetClient(),
 		Recorder:         mgr.GetEventRecorderFor(controller),
-		ReleaseVersion:   getReleaseVersion(),
+		ReleaseVersion:   releaseVersion,
 		ManagedNamespace: *managedNamespace,
+		PlatformType:     platform,
 	}
 }
 
-func setupReconcilers(mgr manager.Manager, platform configv1.PlatformType, containerImages map[string]string, supportedProviders map[string]bool) {
+func setupReconcilers(mgr manager.Manager, platform configv1.PlatformType, containerImages, imageOverrides map[string]string, supportedProviders map[string]bool, caConfigMap client.ObjectKey) {
 	if err := (&clusteroperator.ClusterOperatorReconciler{
-		ClusterOperatorStatusClient: getClusterOperatorStatusClient(mgr, "cluster-capi-operator-cluster-operator-controller"),
+		ClusterOperatorStatusClient: getClusterOperatorStatusClient(mgr, platform, "cluster-capi-operator-cluster-operator-controller"),
 		Scheme:                      mgr.GetScheme(),
 		Images:                      containerImages,
+		ImageOverrides:              imageOverrides,
 		SupportedPlatforms:          supportedProviders,
 	}).SetupWithManager(mgr); err != nil {
 		klog.Error(err, "unable to create controller", "controller", "ClusterOperator")
@@ -213,17 +366,19 @@ func setupReconcilers(mgr manager.Manager, platform configv1.PlatformType, conta
 	}
 
 	if err := (&cluster.CoreClusterReconciler{
-		ClusterOperatorStatusClient: getClusterOperatorStatusClient(mgr, "cluster-capi-operator-cluster-resource-controller"),
+		ClusterOperatorStatusClient: getClusterOperatorStatusClient(mgr, platform, "cluster-capi-operator-cluster-resource-controller"),
 		Cluster:                     &clusterv1.Cluster{},
+		InfraCluster:                infraClusterForPlatform(platform),
+		SupportedPlatforms:          supportedProviders,
 	}).SetupWithManager(mgr); err != nil {
 		klog.Error(err, "unable to create controller", "controller", "CoreCluster")
 		os.Exit(1)
 	}
 
-	setupInfraClusterReconciler(mgr, platform)
+	setupInfraClusterReconciler(mgr, platform, supportedProviders)
 
 	if err := (&secretsync.UserDataSecretController{
-		ClusterOperatorStatusClient: getClusterOperatorStatusClient(mgr, "cluster-capi-operator-user-data-secret-controller"),
+		ClusterOperatorStatusClient: getClusterOperatorStatusClient(mgr, platform, "cluster-capi-operator-user-data-secret-controller"),
 		Scheme:                      mgr.GetScheme(),
 	}).SetupWithManager(mgr); err != nil {
 		klog.Error(err, "unable to create user-data-secret controller", "controller", "ClusterOperator")
@@ -231,59 +386,128 @@ func setupReconcilers(mgr manager.Manager, platform configv1.PlatformType, conta
 	}
 
 	if err := (&kubeconfig.KubeconfigReconciler{
-		ClusterOperatorStatusClient: getClusterOperatorStatusClient(mgr, "cluster-capi-operator-kubeconfig-controller"),
+		ClusterOperatorStatusClient: getClusterOperatorStatusClient(mgr, platform, "cluster-capi-operator-kubeconfig-controller"),
 		Scheme:                      mgr.GetScheme(),
 		SupportedPlatforms:          supportedProviders,
 		RestCfg:                     mgr.GetConfig(),
+		TokenExpiration:             *kubeconfigTokenExpiration,
+		TokenRotationFraction:       *kubeconfigTokenRotationFraction,
+		TokenAudiences:              splitList(*kubeconfigTokenAudiences),
+		CAConfigMap:                 caConfigMap,
 	}).SetupWithManager(mgr); err != nil {
 		klog.Error(err, "unable to create controller", "controller", "ClusterOperator")
 		os.Exit(1)
 	}
 }
 
-func setupInfraClusterReconciler(mgr manager.Manager, platform configv1.PlatformType) {
+// infraClusterForPlatform returns the InfraCluster type referenced by the core Cluster on the given platform,
+// or nil when the platform is not supported.
+func infraClusterForPlatform(platform configv1.PlatformType) client.Object {
+	switch platform {
+	case configv1.AWSPlatformType:
+		return &awsv1.AWSCluster{}
+	case configv1.AzurePlatformType:
+		return &azurev1.AzureCluster{}
+	case configv1.GCPPlatformType:
+		return &gcpv1.GCPCluster{}
+	case configv1.PowerVSPlatformType:
+		return &ibmcloudv1.IBMPowerVSCluster{}
+	case configv1.IBMCloudPlatformType:
+		return &ibmcloudv1.IBMVPCCluster{}
+	default:
+		return nil
+	}
+}
+
+func setupInfraClusterReconciler(mgr manager.Manager, platform configv1.PlatformType, supportedProviders map[string]bool) {
 	switch platform {
 	case configv1.AWSPlatformType:
 		if err := (&cluster.GenericInfraClusterReconciler{
-			ClusterOperatorStatusClient: getClusterOperatorStatusClient(mgr, "cluster-capi-operator-infra-cluster-resource-controller"),
+			ClusterOperatorStatusClient: getClusterOperatorStatusClient(mgr, platform, "cluster-capi-operator-infra-cluster-resource-controller"),
 			InfraCluster:                &awsv1.AWSCluster{},
+			NewInfraCluster:             cluster.NewAWSCluster,
+			ImmutableFields:             cluster.AWSClusterImmutableFields,
+			SupportedPlatforms:          supportedProviders,
 		}).SetupWithManager(mgr); err != nil {
 			klog.Error(err, "unable to create controller", "controller", "AWSCluster")
 			os.Exit(1)
 		}
+	case configv1.AzurePlatformType:
+		if err := (&cluster.GenericInfraClusterReconciler{
+			ClusterOperatorStatusClient: getClusterOperatorStatusClient(mgr, platform, "cluster-capi-operator-infra-cluster-resource-controller"),
+			InfraCluster:                &azurev1.AzureCluster{},
+			NewInfraCluster:             cluster.NewAzureCluster,
+			ImmutableFields:             cluster.AzureClusterImmutableFields,
+			SupportedPlatforms:          supportedProviders,
+		}).SetupWithManager(mgr); err != nil {
+			klog.Error(err, "unable to create controller", "controller", "AzureCluster")
+			os.Exit(1)
+		}
 	case configv1.GCPPlatformType:
 		if err := (&cluster.GenericInfraClusterReconciler{
-			ClusterOperatorStatusClient: getClusterOperatorStatusClient(mgr, "cluster-capi-operator-infra-cluster-resource-controller"),
+			ClusterOperatorStatusClient: getClusterOperatorStatusClient(mgr, platform, "cluster-capi-operator-infra-cluster-resource-controller"),
 			InfraCluster:                &gcpv1.GCPCluster{},
+			NewInfraCluster:             cluster.NewGCPCluster,
+			InitInfraCluster:            cluster.InitGCPCluster,
+			ImmutableFields:             cluster.GCPClusterImmutableFields,
+			SupportedPlatforms:          supportedProviders,
 		}).SetupWithManager(mgr); err != nil {
 			klog.Error(err, "unable to create controller", "controller", "GCPCluster")
 			os.Exit(1)
 		}
 	case configv1.PowerVSPlatformType:
 		if err := (&cluster.GenericInfraClusterReconciler{
-			ClusterOperatorStatusClient: getClusterOperatorStatusClient(mgr, "cluster-capi-operator-infra-cluster-resource-controller"),
-			InfraCluster:                &ibmpowervsv1.IBMPowerVSCluster{},
+			ClusterOperatorStatusClient: getClusterOperatorStatusClient(mgr, platform, "cluster-capi-operator-infra-cluster-resource-controller"),
+			InfraCluster:                &ibmcloudv1.IBMPowerVSCluster{},
+			NewInfraCluster:             cluster.NewIBMPowerVSCluster,
+			InitInfraCluster:            cluster.InitIBMPowerVSCluster,
+			SupportedPlatforms:          supportedProviders,
 		}).SetupWithManager(mgr); err != nil {
 			klog.Error(err, "unable to create controller", "controller", "IBMPowerVSCluster")
 			os.Exit(1)
 		}
+	case configv1.IBMCloudPlatformType:
+		if err := (&cluster.GenericInfraClusterReconciler{
+			ClusterOperatorStatusClient: getClusterOperatorStatusClient(mgr, platform, "cluster-capi-operator-infra-cluster-resource-controller"),
+			InfraCluster:                &ibmcloudv1.IBMVPCCluster{},
+			NewInfraCluster:             cluster.NewIBMVPCCluster,
+			SupportedPlatforms:          supportedProviders,
+		}).SetupWithManager(mgr); err != nil {
+			klog.Error(err, "unable to create controller", "controller", "IBMVPCCluster")
+			os.Exit(1)
+		}
 	default:
 		klog.Info("Platform not supported, skipping infra cluster controller setup")
 	}
 }
 
 func setupWebhooks(mgr ctrl.Manager, platform configv1.PlatformType) {
-	if err := (&webhook.CoreProviderWebhook{}).SetupWebhookWithManager(mgr); err != nil {
+	// The operator runs in the namespace set by the downward API, which defaults to the managed namespace.
+	operatorNamespace := os.Getenv("POD_NAMESPACE")
+	if operatorNamespace == "" {
+		operatorNamespace = *managedNamespace
+	}
+	operatorUsername := webhook.ServiceAccountUsername(operatorNamespace, operatorServiceAccountName)
+
+	if err := (&webhook.CoreProviderWebhook{
+		OperatorUsername: operatorUsername,
+	}).SetupWebhookWithManager(mgr); err != nil {
 		klog.Error(err, "unable to create webhook", "webhook", "CoreProvider")
 		os.Exit(1)
 	}
 
-	if err := (&webhook.InfrastructureProviderWebhook{Platform: platform}).SetupWebhookWithManager(mgr); err != nil {
+	if err := (&webhook.InfrastructureProviderWebhook{
+		Platform:         platform,
+		OperatorUsername: operatorUsername,
+	}).SetupWebhookWithManager(mgr); err != nil {
 		klog.Error(err, "unable to create webhook", "webhook", "InfrastructureProvider")
 		os.Exit(1)
 	}
 
-	if err := (&webhook.ClusterWebhook{}).SetupWebhookWithManager(mgr); err != nil {
+	if err := (&webhook.ClusterWebhook{
+		Client:           mgr.GetAPIReader(),
+		ManagedNamespace: *managedNamespace,
+	}).SetupWebhookWithManager(mgr); err != nil {
 		klog.Error(err, "unable to create webhook", "webhook", "Cluster")
 		os.Exit(1)
 	}
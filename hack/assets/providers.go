@@ -298,6 +298,11 @@ func importProviders(providerName string) error {
 	}
 
 	for _, p := range providers {
+		// The ibmcloud assets are generated from the powervs entry, which uses the same upstream provider
+		if p.Name == ibmCloudProvider {
+			continue
+		}
+
 		fmt.Printf("Processing provider %s: %s\n", p.PType, p.Name)
 
 		// Load manifests from github for specific provider
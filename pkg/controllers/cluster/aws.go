@@ -0,0 +1,52 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	awsv1 "sigs.k8s.io/cluster-api-provider-aws/v2/api/v1beta1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+// AWSClusterImmutableFields are the AWSCluster spec fields CAPA does not allow to change once set. The
+// AWSCluster validating webhook rejects updates of the region and of a set control plane endpoint,
+// see ValidateUpdate in sigs.k8s.io/cluster-api-provider-aws/v2/api/v1beta2/awscluster_webhook.go.
+var AWSClusterImmutableFields = []string{"region", "controlPlaneEndpoint"}
+
+// NewAWSCluster returns an externally managed AWSCluster for an AWS Infrastructure.
+// The user defined resource tags of the cluster are added to the resources managed by the provider.
+func NewAWSCluster(_ context.Context, _ client.Reader, infra *configv1.Infrastructure, _ string) (client.Object, []client.Object, error) {
+	if infra.Status.PlatformStatus == nil || infra.Status.PlatformStatus.AWS == nil {
+		return nil, nil, fmt.Errorf("infrastructure has no AWS platform status")
+	}
+	aws := infra.Status.PlatformStatus.AWS
+
+	endpoint, err := controlPlaneEndpoint(infra)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var tags awsv1.Tags
+	if len(aws.ResourceTags) > 0 {
+		tags = awsv1.Tags{}
+		for _, tag := range aws.ResourceTags {
+			tags[tag.Key] = tag.Value
+		}
+	}
+
+	return &awsv1.AWSCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        infra.Status.InfrastructureName,
+			Annotations: map[string]string{clusterv1.ManagedByAnnotation: ""},
+		},
+		Spec: awsv1.AWSClusterSpec{
+			Region:               aws.Region,
+			ControlPlaneEndpoint: endpoint,
+			AdditionalTags:       tags,
+		},
+	}, nil, nil
+}
@@ -0,0 +1,146 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	azurev1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+const (
+	// azureCredentialsSecretName is the secret created by the cloud credential operator for CAPZ.
+	azureCredentialsSecretName = "capz-manager-bootstrap-credentials"
+
+	azureClientIDKey       = "azure_client_id"
+	azureTenantIDKey       = "azure_tenant_id"
+	azureRegionKey         = "azure_region"
+	azureSubscriptionIDKey = "azure_subscription_id"
+)
+
+// AzureClusterImmutableFields are the AzureCluster spec fields CAPZ does not allow to change once set. The
+// AzureCluster validating webhook rejects updates of these fields, including the host and port of a set
+// control plane endpoint, see ValidateUpdate in sigs.k8s.io/cluster-api-provider-azure/api/v1beta1/azurecluster_webhook.go.
+var AzureClusterImmutableFields = []string{"resourceGroup", "subscriptionID", "location", "azureEnvironment", "controlPlaneEndpoint"}
+
+// NewAzureCluster returns an externally managed AzureCluster for an Azure Infrastructure, together with
+// the AzureClusterIdentity using the CAPZ credentials.
+func NewAzureCluster(ctx context.Context, cl client.Reader, infra *configv1.Infrastructure, namespace string) (client.Object, []client.Object, error) {
+	if infra.Status.PlatformStatus == nil || infra.Status.PlatformStatus.Azure == nil {
+		return nil, nil, fmt.Errorf("infrastructure has no Azure platform status")
+	}
+	azure := infra.Status.PlatformStatus.Azure
+
+	environment, err := azureEnvironment(azure.CloudName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	credentials := &corev1.Secret{}
+	if err := cl.Get(ctx, client.ObjectKey{Namespace: namespace, Name: azureCredentialsSecretName}, credentials); err != nil {
+		return nil, nil, fmt.Errorf("unable to get Azure credentials secret %s/%s: %v", namespace, azureCredentialsSecretName, err)
+	}
+
+	credentialValues := map[string]string{}
+	for _, key := range []string{azureClientIDKey, azureTenantIDKey, azureRegionKey, azureSubscriptionIDKey} {
+		value, ok := credentials.Data[key]
+		if !ok || len(value) == 0 {
+			return nil, nil, fmt.Errorf("credentials secret %s/%s is missing %q", namespace, azureCredentialsSecretName, key)
+		}
+		credentialValues[key] = string(value)
+	}
+
+	endpoint, err := controlPlaneEndpoint(infra)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	identity := &azurev1.AzureClusterIdentity{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      infra.Status.InfrastructureName,
+			Namespace: namespace,
+		},
+		Spec: azurev1.AzureClusterIdentitySpec{
+			Type:     azurev1.ServicePrincipal,
+			ClientID: credentialValues[azureClientIDKey],
+			TenantID: credentialValues[azureTenantIDKey],
+			ClientSecret: corev1.SecretReference{
+				Name:      azureCredentialsSecretName,
+				Namespace: namespace,
+			},
+			AllowedNamespaces: &azurev1.AllowedNamespaces{
+				NamespaceList: []string{namespace},
+			},
+		},
+	}
+
+	// The network resource group differs from the cluster one when installed into an existing VNet
+	networkResourceGroup := azure.NetworkResourceGroupName
+	if networkResourceGroup == "" {
+		networkResourceGroup = azure.ResourceGroupName
+	}
+
+	azureCluster := &azurev1.AzureCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        infra.Status.InfrastructureName,
+			Annotations: map[string]string{clusterv1.ManagedByAnnotation: ""},
+		},
+		Spec: azurev1.AzureClusterSpec{
+			AzureClusterClassSpec: azurev1.AzureClusterClassSpec{
+				SubscriptionID: credentialValues[azureSubscriptionIDKey],
+				Location:       credentialValues[azureRegionKey],
+				IdentityRef: &corev1.ObjectReference{
+					APIVersion: azurev1.GroupVersion.String(),
+					Kind:       "AzureClusterIdentity",
+					Name:       identity.Name,
+					Namespace:  identity.Namespace,
+				},
+				AzureEnvironment: environment,
+			},
+			ResourceGroup: azure.ResourceGroupName,
+			NetworkSpec: azurev1.NetworkSpec{
+				// Names follow the installer conventions for the cluster network
+				Vnet: azurev1.VnetSpec{
+					ResourceGroup: networkResourceGroup,
+					Name:          fmt.Sprintf("%s-vnet", infra.Status.InfrastructureName),
+				},
+				Subnets: azurev1.Subnets{
+					{
+						SubnetClassSpec: azurev1.SubnetClassSpec{
+							Name: fmt.Sprintf("%s-master-subnet", infra.Status.InfrastructureName),
+							Role: azurev1.SubnetControlPlane,
+						},
+					},
+					{
+						SubnetClassSpec: azurev1.SubnetClassSpec{
+							Name: fmt.Sprintf("%s-worker-subnet", infra.Status.InfrastructureName),
+							Role: azurev1.SubnetNode,
+						},
+					},
+				},
+			},
+			ControlPlaneEndpoint: endpoint,
+		},
+	}
+
+	return azureCluster, []client.Object{identity}, nil
+}
+
+// azureEnvironment returns the CAPZ Azure environment for the cloud of the cluster.
+// CAPZ uses the same environment names as the Infrastructure, defaulting to the public cloud.
+// Azure Stack Hub is not supported, as CAPZ reads its endpoints from an environment file that is not configured.
+func azureEnvironment(cloudName configv1.AzureCloudEnvironment) (string, error) {
+	switch cloudName {
+	case "":
+		return string(configv1.AzurePublicCloud), nil
+	case configv1.AzureStackCloud:
+		return "", newUnsupportedInfraClusterError("azure cloud environment %s requires an environment file for the provider, which is not configured", cloudName)
+	}
+
+	return string(cloudName), nil
+}
@@ -4,43 +4,110 @@ import (
 	"context"
 	"fmt"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/util/conditions"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/cluster-capi-operator/pkg/controllers"
 	"github.com/openshift/cluster-capi-operator/pkg/operatorstatus"
 	"github.com/openshift/cluster-capi-operator/pkg/util"
 )
 
+const (
+	// coreClusterControllerName prefixes the ClusterOperator conditions reporting the state of the controller.
+	coreClusterControllerName = "CoreClusterController"
+
+	clusterCreatedReason              = "ClusterCreated"
+	clusterInfrastructureRefReason    = "InfrastructureRefCorrected"
+	clusterControlPlaneEndpointReason = "ControlPlaneEndpointCorrected"
+)
+
 type CoreClusterReconciler struct {
 	operatorstatus.ClusterOperatorStatusClient
 	Cluster *clusterv1.Cluster
+	// InfraCluster is the InfraCluster type of the platform. When set, the Cluster named after the
+	// infrastructure name is created in the managed namespace and its infrastructure reference is kept pointing to it.
+	InfraCluster client.Object
+	// SupportedPlatforms are the platforms whose infrastructure provider is enabled unless overridden.
+	SupportedPlatforms map[string]bool
 }
 
 func (r *CoreClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(r.Cluster).
+		Watches(
+			&source.Kind{Type: &configv1.Infrastructure{}},
+			handler.EnqueueRequestsFromMapFunc(r.toCluster),
+			builder.WithPredicates(infrastructurePredicates()),
+		).
+		Watches(
+			&source.Kind{Type: &corev1.ConfigMap{}},
+			handler.EnqueueRequestsFromMapFunc(r.providerOverridesToCluster),
+			builder.WithPredicates(providerOverridesPredicates(r.ManagedNamespace)),
+		).
 		Complete(r)
 }
 
 func (r *CoreClusterReconciler) Reconcile(ctx context.Context, req reconcile.Request) (ctrl.Result, error) {
 	log := ctrl.LoggerFrom(ctx).WithName("CoreClusterController")
 
+	enabled, err := util.IsProviderEnabled(ctx, r.Client, r.ManagedNamespace, r.SupportedPlatforms, r.PlatformType)
+	if err != nil {
+		log.Error(err, "Error determining if the infrastructure provider is enabled")
+		if err := r.SetControllerDegraded(ctx, coreClusterControllerName, err); err != nil {
+			return ctrl.Result{}, fmt.Errorf("error syncing ClusterOperatorStatus: %v", err)
+		}
+		return ctrl.Result{}, err
+	}
+	if !enabled {
+		log.Info("Infrastructure provider is disabled. Skipping core cluster reconciliation...", "platformType", r.PlatformType)
+		return ctrl.Result{}, r.SetControllerAvailable(ctx, coreClusterControllerName)
+	}
+
 	cluster := &clusterv1.Cluster{}
+	if err := r.Client.Get(ctx, req.NamespacedName, cluster); errors.IsNotFound(err) {
+		created, err := r.createCluster(ctx, req)
+		if err != nil {
+			log.Error(err, "Error creating core cluster")
+			if err := r.SetControllerDegraded(ctx, coreClusterControllerName, err); err != nil {
+				return ctrl.Result{}, fmt.Errorf("error syncing ClusterOperatorStatus: %v", err)
+			}
+			return ctrl.Result{}, err
+		}
 
-	if err := r.Client.Get(ctx, req.NamespacedName, cluster); err != nil && !errors.IsNotFound(err) {
+		if created == nil {
+			return ctrl.Result{}, nil
+		}
+		cluster = created
+	} else if err != nil {
 		return ctrl.Result{}, err
 	}
 
 	if !cluster.DeletionTimestamp.IsZero() {
-		return ctrl.Result{}, r.SetStatusAvailable(ctx)
+		return ctrl.Result{}, r.SetControllerAvailable(ctx, coreClusterControllerName)
 	}
 
 	log.Info("Reconciling core cluster")
 
+	if err := r.syncClusterSpec(ctx, cluster); err != nil {
+		log.Error(err, "Error syncing core cluster spec")
+		if err := r.SetControllerDegraded(ctx, coreClusterControllerName, err); err != nil {
+			return ctrl.Result{}, fmt.Errorf("error syncing ClusterOperatorStatus: %v", err)
+		}
+		return ctrl.Result{}, err
+	}
+
 	clusterCopy := cluster.DeepCopy()
 
 	conditions.MarkTrue(cluster, clusterv1.ControlPlaneInitializedCondition)
@@ -57,5 +124,147 @@ func (r *CoreClusterReconciler) Reconcile(ctx context.Context, req reconcile.Req
 		}
 	}
 
-	return ctrl.Result{}, r.SetStatusAvailable(ctx)
+	return ctrl.Result{}, r.SetControllerAvailable(ctx, coreClusterControllerName)
+}
+
+// createCluster creates the managed Cluster when the request targets it. It returns nil when the request
+// is for another Cluster or when the managed Cluster cannot be described yet.
+func (r *CoreClusterReconciler) createCluster(ctx context.Context, req reconcile.Request) (*clusterv1.Cluster, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	infra, err := r.managedInfrastructure(ctx, req.NamespacedName)
+	if err != nil || infra == nil {
+		return nil, err
+	}
+
+	cluster, err := r.desiredCluster(infra)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.Client.Create(ctx, cluster); err != nil {
+		return nil, fmt.Errorf("unable to create core cluster: %v", err)
+	}
+
+	log.Info("Created core cluster", "name", cluster.Name)
+	r.Recorder.Eventf(cluster, corev1.EventTypeNormal, clusterCreatedReason, "Created core cluster %s", cluster.Name)
+
+	return cluster, nil
+}
+
+// syncClusterSpec reverts changes to the infrastructure reference and the control plane endpoint of the managed Cluster.
+func (r *CoreClusterReconciler) syncClusterSpec(ctx context.Context, cluster *clusterv1.Cluster) error {
+	infra, err := r.managedInfrastructure(ctx, client.ObjectKeyFromObject(cluster))
+	if err != nil || infra == nil {
+		return err
+	}
+
+	desired, err := r.desiredCluster(infra)
+	if err != nil {
+		return err
+	}
+
+	clusterCopy := cluster.DeepCopy()
+
+	infraRefChanged := !equality.Semantic.DeepEqual(cluster.Spec.InfrastructureRef, desired.Spec.InfrastructureRef)
+	if infraRefChanged {
+		cluster.Spec.InfrastructureRef = desired.Spec.InfrastructureRef
+	}
+
+	// While the API server internal URL is not populated, the control plane endpoint is left to Cluster API.
+	endpointChanged := !desired.Spec.ControlPlaneEndpoint.IsZero() && cluster.Spec.ControlPlaneEndpoint != desired.Spec.ControlPlaneEndpoint
+	if endpointChanged {
+		cluster.Spec.ControlPlaneEndpoint = desired.Spec.ControlPlaneEndpoint
+	}
+
+	if !infraRefChanged && !endpointChanged {
+		return nil
+	}
+
+	if err := r.Client.Patch(ctx, cluster, client.MergeFrom(clusterCopy)); err != nil {
+		return fmt.Errorf("unable to patch core cluster spec: %v", err)
+	}
+
+	if infraRefChanged {
+		r.Recorder.Eventf(cluster, corev1.EventTypeWarning, clusterInfrastructureRefReason,
+			"Reverted infrastructure reference of core cluster to %s %s", desired.Spec.InfrastructureRef.Kind, desired.Spec.InfrastructureRef.Name)
+	}
+	if endpointChanged {
+		r.Recorder.Eventf(cluster, corev1.EventTypeWarning, clusterControlPlaneEndpointReason,
+			"Reverted control plane endpoint of core cluster to %s:%d", desired.Spec.ControlPlaneEndpoint.Host, desired.Spec.ControlPlaneEndpoint.Port)
+	}
+
+	return nil
+}
+
+// managedInfrastructure returns the cluster Infrastructure when the key refers to the Cluster managed by the operator.
+// It returns nil when the Cluster is not managed or when the Infrastructure does not exist.
+func (r *CoreClusterReconciler) managedInfrastructure(ctx context.Context, key client.ObjectKey) (*configv1.Infrastructure, error) {
+	if r.InfraCluster == nil || key.Namespace != r.ManagedNamespace {
+		return nil, nil
+	}
+
+	infra := &configv1.Infrastructure{}
+	if err := r.Client.Get(ctx, client.ObjectKey{Name: controllers.InfrastructureResourceName}, infra); errors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to get cluster infrastructure: %v", err)
+	}
+
+	if infra.Status.InfrastructureName == "" || infra.Status.InfrastructureName != key.Name {
+		return nil, nil
+	}
+
+	return infra, nil
+}
+
+// desiredCluster returns the managed Cluster, which references the InfraCluster named after the infrastructure name.
+func (r *CoreClusterReconciler) desiredCluster(infra *configv1.Infrastructure) (*clusterv1.Cluster, error) {
+	gvk, err := apiutil.GVKForObject(r.InfraCluster, r.Client.Scheme())
+	if err != nil {
+		return nil, fmt.Errorf("unable to get InfraCluster kind: %v", err)
+	}
+
+	endpoint, err := controlPlaneEndpoint(infra)
+	if err != nil {
+		return nil, err
+	}
+
+	return &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      infra.Status.InfrastructureName,
+			Namespace: r.ManagedNamespace,
+		},
+		Spec: clusterv1.ClusterSpec{
+			InfrastructureRef: &corev1.ObjectReference{
+				APIVersion: gvk.GroupVersion().String(),
+				Kind:       gvk.Kind,
+				Name:       infra.Status.InfrastructureName,
+				Namespace:  r.ManagedNamespace,
+			},
+			ControlPlaneEndpoint: endpoint,
+		},
+	}, nil
+}
+
+// toCluster maps the cluster Infrastructure to the Cluster named after its infrastructure name.
+func (r *CoreClusterReconciler) toCluster(obj client.Object) []reconcile.Request {
+	infra, ok := obj.(*configv1.Infrastructure)
+	if !ok || infra.Status.InfrastructureName == "" {
+		return nil
+	}
+
+	return []reconcile.Request{{
+		NamespacedName: client.ObjectKey{Namespace: r.ManagedNamespace, Name: infra.Status.InfrastructureName},
+	}}
+}
+
+// providerOverridesToCluster maps the provider overrides ConfigMap to the Cluster of the cluster Infrastructure.
+func (r *CoreClusterReconciler) providerOverridesToCluster(client.Object) []reconcile.Request {
+	infra := &configv1.Infrastructure{}
+	if err := r.Client.Get(context.Background(), client.ObjectKey{Name: controllers.InfrastructureResourceName}, infra); err != nil {
+		return nil
+	}
+
+	return r.toCluster(infra)
 }
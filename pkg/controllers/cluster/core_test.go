@@ -6,13 +6,18 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ibmcloudv1 "sigs.k8s.io/cluster-api-provider-ibmcloud/api/v1beta1"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
+	configv1 "github.com/openshift/api/config/v1"
 	"github.com/openshift/cluster-capi-operator/pkg/controllers"
 	"github.com/openshift/cluster-capi-operator/pkg/operatorstatus"
 	"github.com/openshift/cluster-capi-operator/pkg/test"
+	"github.com/openshift/cluster-capi-operator/pkg/util"
 )
 
 var _ = Describe("Reconcile Core cluster", func() {
@@ -22,9 +27,11 @@ var _ = Describe("Reconcile Core cluster", func() {
 	BeforeEach(func() {
 		r = &CoreClusterReconciler{
 			ClusterOperatorStatusClient: operatorstatus.ClusterOperatorStatusClient{
-				Client: cl,
+				Client:       cl,
+				PlatformType: configv1.AWSPlatformType,
 			},
-			Cluster: &clusterv1.Cluster{},
+			Cluster:            &clusterv1.Cluster{},
+			SupportedPlatforms: map[string]bool{"aws": true},
 		}
 
 		coreCluster = &clusterv1.Cluster{
@@ -60,3 +67,129 @@ var _ = Describe("Reconcile Core cluster", func() {
 		Expect(coreCluster.Status.Conditions[0].Status).To(Equal(corev1.ConditionTrue))
 	})
 })
+
+var _ = Describe("Reconcile managed core cluster", func() {
+	var r *CoreClusterReconciler
+	var recorder *record.FakeRecorder
+	var infra *configv1.Infrastructure
+	clusterKey := client.ObjectKey{Name: "test-infra-name", Namespace: controllers.DefaultManagedNamespace}
+
+	BeforeEach(func() {
+		infra = &configv1.Infrastructure{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: controllers.InfrastructureResourceName,
+			},
+		}
+		Expect(cl.Create(ctx, infra)).To(Succeed())
+
+		infra.Status = configv1.InfrastructureStatus{
+			InfrastructureName:   clusterKey.Name,
+			APIServerInternalURL: "https://api-int.test.example.com:6443",
+		}
+		Expect(cl.Status().Update(ctx, infra)).To(Succeed())
+
+		recorder = record.NewFakeRecorder(32)
+		r = &CoreClusterReconciler{
+			ClusterOperatorStatusClient: operatorstatus.ClusterOperatorStatusClient{
+				Client:           cl,
+				Recorder:         recorder,
+				ManagedNamespace: controllers.DefaultManagedNamespace,
+				PlatformType:     configv1.IBMCloudPlatformType,
+			},
+			Cluster:            &clusterv1.Cluster{},
+			InfraCluster:       &ibmcloudv1.IBMVPCCluster{},
+			SupportedPlatforms: map[string]bool{"ibmcloud": true},
+		}
+	})
+
+	AfterEach(func() {
+		coreCluster := &clusterv1.Cluster{}
+		coreCluster.SetName(clusterKey.Name)
+		coreCluster.SetNamespace(clusterKey.Namespace)
+		co := &configv1.ClusterOperator{}
+		co.SetName(controllers.ClusterOperatorName)
+		Expect(test.CleanupAndWait(ctx, cl, coreCluster, infra, co)).To(Succeed())
+	})
+
+	It("should create the core cluster referencing the infrastructure cluster", func() {
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: clusterKey})
+		Expect(err).ToNot(HaveOccurred())
+
+		coreCluster := &clusterv1.Cluster{}
+		Expect(cl.Get(ctx, clusterKey, coreCluster)).To(Succeed())
+		Expect(coreCluster.Spec.InfrastructureRef).To(Equal(&corev1.ObjectReference{
+			APIVersion: ibmcloudv1.GroupVersion.String(),
+			Kind:       "IBMVPCCluster",
+			Name:       clusterKey.Name,
+			Namespace:  clusterKey.Namespace,
+		}))
+		Expect(coreCluster.Spec.ControlPlaneEndpoint).To(Equal(clusterv1.APIEndpoint{Host: "api-int.test.example.com", Port: 6443}))
+		Expect(conditions.IsTrue(coreCluster, clusterv1.ControlPlaneInitializedCondition)).To(BeTrue())
+		Expect(recorder.Events).To(Receive(ContainSubstring(clusterCreatedReason)))
+	})
+
+	It("should recreate a deleted core cluster", func() {
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: clusterKey})
+		Expect(err).ToNot(HaveOccurred())
+
+		coreCluster := &clusterv1.Cluster{}
+		Expect(cl.Get(ctx, clusterKey, coreCluster)).To(Succeed())
+		originalUID := coreCluster.GetUID()
+		Expect(test.CleanupAndWait(ctx, cl, coreCluster)).To(Succeed())
+
+		_, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: clusterKey})
+		Expect(err).ToNot(HaveOccurred())
+
+		recreated := &clusterv1.Cluster{}
+		Expect(cl.Get(ctx, clusterKey, recreated)).To(Succeed())
+		Expect(recreated.GetUID()).ToNot(Equal(originalUID))
+	})
+
+	It("should revert changes to the infrastructure reference and the control plane endpoint", func() {
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: clusterKey})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(recorder.Events).To(Receive(ContainSubstring(clusterCreatedReason)))
+
+		coreCluster := &clusterv1.Cluster{}
+		Expect(cl.Get(ctx, clusterKey, coreCluster)).To(Succeed())
+		coreCluster.Spec.InfrastructureRef.Name = "other"
+		coreCluster.Spec.ControlPlaneEndpoint.Port = 8443
+		Expect(cl.Update(ctx, coreCluster)).To(Succeed())
+
+		_, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: clusterKey})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(cl.Get(ctx, clusterKey, coreCluster)).To(Succeed())
+		Expect(coreCluster.Spec.InfrastructureRef.Name).To(Equal(clusterKey.Name))
+		Expect(coreCluster.Spec.ControlPlaneEndpoint.Port).To(BeEquivalentTo(6443))
+		Expect(recorder.Events).To(Receive(ContainSubstring(clusterInfrastructureRefReason)))
+		Expect(recorder.Events).To(Receive(ContainSubstring(clusterControlPlaneEndpointReason)))
+	})
+
+	It("should not create the core cluster when the infrastructure provider is disabled", func() {
+		overrides := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      util.ProviderOverridesConfigMapName,
+				Namespace: controllers.DefaultManagedNamespace,
+			},
+			Data: map[string]string{util.DisabledProvidersKey: "ibmcloud"},
+		}
+		Expect(cl.Create(ctx, overrides)).To(Succeed())
+		defer func() {
+			Expect(test.CleanupAndWait(ctx, cl, overrides)).To(Succeed())
+		}()
+
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: clusterKey})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(cl.Get(ctx, clusterKey, &clusterv1.Cluster{})).NotTo(Succeed())
+	})
+
+	It("should not create clusters other than the managed one", func() {
+		otherKey := client.ObjectKey{Name: "other", Namespace: clusterKey.Namespace}
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: otherKey})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(cl.Get(ctx, otherKey, &clusterv1.Cluster{})).NotTo(Succeed())
+	})
+})
@@ -0,0 +1,104 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	gcpv1 "sigs.k8s.io/cluster-api-provider-gcp/api/v1beta1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/cluster-capi-operator/pkg/controllers"
+)
+
+var machineSetListGVK = schema.GroupVersionKind{Group: "machine.openshift.io", Version: "v1beta1", Kind: "MachineSetList"}
+
+// GCPClusterImmutableFields are the GCPCluster spec fields CAPG does not allow to change.
+var GCPClusterImmutableFields = []string{"project", "region"}
+
+// NewGCPCluster returns an externally managed GCPCluster for a GCP Infrastructure.
+func NewGCPCluster(_ context.Context, _ client.Reader, infra *configv1.Infrastructure, _ string) (client.Object, []client.Object, error) {
+	if infra.Status.PlatformStatus == nil || infra.Status.PlatformStatus.GCP == nil {
+		return nil, nil, fmt.Errorf("infrastructure has no GCP platform status")
+	}
+	gcp := infra.Status.PlatformStatus.GCP
+
+	endpoint, err := controlPlaneEndpoint(infra)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &gcpv1.GCPCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        infra.Status.InfrastructureName,
+			Annotations: map[string]string{clusterv1.ManagedByAnnotation: ""},
+		},
+		Spec: gcpv1.GCPClusterSpec{
+			Project:              gcp.ProjectID,
+			Region:               gcp.Region,
+			ControlPlaneEndpoint: endpoint,
+		},
+	}, nil, nil
+}
+
+// InitGCPCluster sets the network of a new GCPCluster. The network is only looked up at creation, so
+// later changes to the MachineSets do not move an existing cluster to another network.
+func InitGCPCluster(ctx context.Context, cl client.Reader, infra *configv1.Infrastructure, infraCluster client.Object) error {
+	gcpCluster, ok := infraCluster.(*gcpv1.GCPCluster)
+	if !ok {
+		return fmt.Errorf("expected a GCPCluster, got %T", infraCluster)
+	}
+
+	network, err := gcpNetworkName(ctx, cl, infra)
+	if err != nil {
+		return err
+	}
+	gcpCluster.Spec.Network.Name = &network
+
+	return nil
+}
+
+// gcpNetworkName returns the name of the cluster network. A network created before the cluster is not named
+// after it, so the network used by the Machine API MachineSets takes precedence. CAPG looks the network up in the
+// project of the cluster and has no field for the host project of a shared VPC, so the GCPCluster is not created for
+// such a network (see NetworkName and NetworkLink of the CAPG ClusterScope in cloud/scope/cluster.go).
+func gcpNetworkName(ctx context.Context, cl client.Reader, infra *configv1.Infrastructure) (string, error) {
+	projectID := infra.Status.PlatformStatus.GCP.ProjectID
+
+	machineSets := &unstructured.UnstructuredList{}
+	machineSets.SetGroupVersionKind(machineSetListGVK)
+	if err := cl.List(ctx, machineSets, client.InNamespace(controllers.MachineAPINamespace)); err != nil && !meta.IsNoMatchError(err) {
+		return "", fmt.Errorf("unable to list Machine API MachineSets: %v", err)
+	}
+
+	for _, machineSet := range machineSets.Items {
+		networkInterfaces, _, err := unstructured.NestedSlice(machineSet.Object, "spec", "template", "spec", "providerSpec", "value", "networkInterfaces")
+		if err != nil {
+			return "", fmt.Errorf("unable to read network interfaces of MachineSet %s: %v", machineSet.GetName(), err)
+		}
+
+		for _, networkInterface := range networkInterfaces {
+			networkInterfaceMap, ok := networkInterface.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			network, ok := networkInterfaceMap["network"].(string)
+			if !ok || network == "" {
+				continue
+			}
+
+			if hostProjectID, ok := networkInterfaceMap["projectID"].(string); ok && hostProjectID != "" && hostProjectID != projectID {
+				return "", newUnsupportedInfraClusterError("network %s of MachineSet %s belongs to the shared VPC host project %s, which is not supported by GCPCluster",
+					network, machineSet.GetName(), hostProjectID)
+			}
+			return network, nil
+		}
+	}
+
+	return fmt.Sprintf("%s-network", infra.Status.InfrastructureName), nil
+}
@@ -0,0 +1,43 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ibmcloudv1 "sigs.k8s.io/cluster-api-provider-ibmcloud/api/v1beta1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+// NewIBMVPCCluster returns an externally managed IBMVPCCluster for an IBMCloud Infrastructure.
+func NewIBMVPCCluster(_ context.Context, _ client.Reader, infra *configv1.Infrastructure, _ string) (client.Object, []client.Object, error) {
+	if infra.Status.PlatformStatus == nil || infra.Status.PlatformStatus.IBMCloud == nil {
+		return nil, nil, fmt.Errorf("infrastructure has no IBMCloud platform status")
+	}
+	ibmCloud := infra.Status.PlatformStatus.IBMCloud
+
+	// Only VPC clusters can be managed by the Cluster API provider
+	if ibmCloud.ProviderType == configv1.IBMCloudProviderTypeClassic {
+		return nil, nil, fmt.Errorf("unsupported IBMCloud provider type: %s", ibmCloud.ProviderType)
+	}
+
+	endpoint, err := controlPlaneEndpoint(infra)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &ibmcloudv1.IBMVPCCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        infra.Status.InfrastructureName,
+			Annotations: map[string]string{clusterv1.ManagedByAnnotation: ""},
+		},
+		Spec: ibmcloudv1.IBMVPCClusterSpec{
+			Region:               ibmCloud.Location,
+			ResourceGroup:        ibmCloud.ResourceGroupName,
+			ControlPlaneEndpoint: endpoint,
+		},
+	}, nil, nil
+}
@@ -2,41 +2,153 @@ package cluster
 
 import (
 	"context"
+	goerrors "errors"
 	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/json"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/cluster-capi-operator/pkg/controllers"
 	"github.com/openshift/cluster-capi-operator/pkg/operatorstatus"
 	"github.com/openshift/cluster-capi-operator/pkg/util"
+	"github.com/openshift/library-go/pkg/config/clusteroperator/v1helpers"
 )
 
+// defaultAPIServerPort is used when the API server internal URL has no explicit port.
+const defaultAPIServerPort = 6443
+
+const (
+	// infraClusterControllerName prefixes the ClusterOperator conditions reporting the state of the controller.
+	infraClusterControllerName = "InfraClusterController"
+
+	infraClusterCreatedReason       = "InfraClusterCreated"
+	infraClusterNotSupportedReason  = "InfraClusterNotSupported"
+	infraClusterSpecCorrectedReason = "InfraClusterSpecCorrected"
+
+	// infraClusterSpecRevertedCondition is the ClusterOperator condition recording the latest revert of the InfraCluster spec.
+	infraClusterSpecRevertedCondition = "InfraClusterSpecReverted"
+
+	// lastAppliedSpecAnnotation records the spec generated from the Infrastructure that was last applied to the InfraCluster,
+	// to tell manual modifications apart from updates of the Infrastructure.
+	lastAppliedSpecAnnotation = "cluster-capi-operator.openshift.io/last-applied-spec"
+)
+
+// unsupportedInfraClusterError is returned by an InfraCluster builder or initializer when the cluster cannot be
+// described by the InfraCluster of the provider. The InfraCluster is then not created, without degrading the operator.
+type unsupportedInfraClusterError struct {
+	message string
+}
+
+func (e *unsupportedInfraClusterError) Error() string {
+	return e.message
+}
+
+func newUnsupportedInfraClusterError(format string, args ...interface{}) error {
+	return &unsupportedInfraClusterError{message: fmt.Sprintf(format, args...)}
+}
+
+// InfraClusterBuilder builds the desired InfraCluster in the given namespace from the cluster Infrastructure.
+// Objects the InfraCluster depends on, such as a cluster identity, are returned alongside it.
+type InfraClusterBuilder func(ctx context.Context, cl client.Reader, infra *configv1.Infrastructure, namespace string) (client.Object, []client.Object, error)
+
+// InfraClusterInitializer sets the fields of a new InfraCluster that are only derived when it is created,
+// such as the ones read from the Machine API resources.
+type InfraClusterInitializer func(ctx context.Context, cl client.Reader, infra *configv1.Infrastructure, infraCluster client.Object) error
+
 type GenericInfraClusterReconciler struct {
 	operatorstatus.ClusterOperatorStatusClient
 	InfraCluster client.Object
+	// NewInfraCluster, when set, is used to create the InfraCluster and its dependencies if it does not exist yet.
+	NewInfraCluster InfraClusterBuilder
+	// InitInfraCluster, when set, completes the InfraCluster generated by NewInfraCluster before it is created.
+	InitInfraCluster InfraClusterInitializer
+	// ImmutableFields are the top level spec fields the provider does not allow to change once set.
+	// They are only set while empty, and a difference from the generated values is reported instead of reverted.
+	ImmutableFields []string
+	// SupportedPlatforms are the platforms whose infrastructure provider is enabled unless overridden.
+	SupportedPlatforms map[string]bool
 }
 
 func (r *GenericInfraClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(r.InfraCluster).
+		Watches(
+			&source.Kind{Type: &configv1.Infrastructure{}},
+			handler.EnqueueRequestsFromMapFunc(r.toInfraCluster),
+			builder.WithPredicates(infrastructurePredicates()),
+		).
+		Watches(
+			&source.Kind{Type: &corev1.ConfigMap{}},
+			handler.EnqueueRequestsFromMapFunc(r.providerOverridesToInfraCluster),
+			builder.WithPredicates(providerOverridesPredicates(r.ManagedNamespace)),
+		).
 		Complete(r)
 }
 
 func (r *GenericInfraClusterReconciler) Reconcile(ctx context.Context, req reconcile.Request) (ctrl.Result, error) {
 	log := ctrl.LoggerFrom(ctx).WithName("InfraClusterController")
 
+	enabled, err := util.IsProviderEnabled(ctx, r.Client, r.ManagedNamespace, r.SupportedPlatforms, r.PlatformType)
+	if err != nil {
+		log.Error(err, "unable to determine if the infrastructure provider is enabled")
+		if err := r.SetControllerDegraded(ctx, infraClusterControllerName, err); err != nil {
+			return ctrl.Result{}, fmt.Errorf("error syncing ClusterOperatorStatus: %v", err)
+		}
+		return ctrl.Result{}, err
+	}
+	if !enabled {
+		log.Info("Infrastructure provider is disabled. Skipping infrastructure cluster reconciliation...", "platformType", r.PlatformType)
+		return ctrl.Result{}, r.SetControllerAvailable(ctx, infraClusterControllerName)
+	}
+
 	infraClusterCopy := r.InfraCluster.DeepCopyObject().(client.Object)
-	if err := r.Client.Get(ctx, req.NamespacedName, infraClusterCopy); err != nil && !errors.IsNotFound(err) {
+	if err := r.Client.Get(ctx, req.NamespacedName, infraClusterCopy); errors.IsNotFound(err) {
+		if r.NewInfraCluster == nil {
+			return ctrl.Result{}, nil
+		}
+
+		infraClusterCopy, err = r.createInfraCluster(ctx, req)
+		var unsupported *unsupportedInfraClusterError
+		if goerrors.As(err, &unsupported) {
+			log.Info("Infrastructure cluster is not supported. Skipping infrastructure cluster creation...", "reason", unsupported.Error())
+			infra := &configv1.Infrastructure{}
+			infra.SetName(controllers.InfrastructureResourceName)
+			r.Recorder.Eventf(infra, corev1.EventTypeWarning, infraClusterNotSupportedReason,
+				"Infrastructure cluster %s is not created: %s", req.Name, unsupported.Error())
+			return ctrl.Result{}, r.SetControllerAvailable(ctx, infraClusterControllerName)
+		} else if err != nil {
+			log.Error(err, "unable to create infrastructure cluster")
+			if err := r.SetControllerDegraded(ctx, infraClusterControllerName, err); err != nil {
+				return ctrl.Result{}, fmt.Errorf("error syncing ClusterOperatorStatus: %v", err)
+			}
+			return ctrl.Result{}, err
+		}
+		if infraClusterCopy == nil {
+			return ctrl.Result{}, nil
+		}
+	} else if err != nil {
 		return ctrl.Result{}, err
 	}
 
 	if !infraClusterCopy.GetDeletionTimestamp().IsZero() {
-		return ctrl.Result{}, r.SetStatusAvailable(ctx)
+		return ctrl.Result{}, r.SetControllerAvailable(ctx, infraClusterControllerName)
 	}
 
 	log.Info("Reconciling infrastructure cluster")
@@ -49,8 +161,18 @@ func (r *GenericInfraClusterReconciler) Reconcile(ctx context.Context, req recon
 	// Set externally managed annotation
 	infraClusterCopy.SetAnnotations(setManagedByAnnotation(infraClusterCopy.GetAnnotations()))
 
+	// Keep the spec in sync with the Infrastructure
+	correctedFields, immutableFields, err := r.syncInfraClusterSpec(ctx, infraClusterCopy)
+	if err != nil {
+		log.Error(err, "unable to sync infrastructure cluster spec")
+		if err := r.SetControllerDegraded(ctx, infraClusterControllerName, err); err != nil {
+			return ctrl.Result{}, fmt.Errorf("error syncing ClusterOperatorStatus: %v", err)
+		}
+		return ctrl.Result{}, err
+	}
+
 	patch := client.MergeFrom(infraClusterPatchCopy)
-	isRequired, err := util.IsPatchRequired(r.InfraCluster, patch)
+	isRequired, err := util.IsPatchRequired(infraClusterCopy, patch)
 	if err != nil {
 		return ctrl.Result{}, fmt.Errorf("failed to check if patch required: %w", err)
 	}
@@ -59,6 +181,16 @@ func (r *GenericInfraClusterReconciler) Reconcile(ctx context.Context, req recon
 		if err := r.Client.Patch(ctx, infraClusterCopy, client.MergeFrom(infraClusterPatchCopy)); err != nil {
 			return ctrl.Result{}, fmt.Errorf("unable to patch infra cluster: %v", err)
 		}
+
+		if len(correctedFields) > 0 {
+			log.Info("Reverted infrastructure cluster spec", "fields", correctedFields)
+			r.Recorder.Event(infraClusterCopy, corev1.EventTypeWarning, infraClusterSpecCorrectedReason,
+				specRevertedMessage(infraClusterCopy.GetName(), correctedFields))
+		}
+	}
+
+	if err := r.setSpecRevertedCondition(ctx, infraClusterCopy.GetName(), correctedFields); err != nil {
+		return ctrl.Result{}, fmt.Errorf("error syncing ClusterOperatorStatus: %v", err)
 	}
 
 	// Set status to ready
@@ -87,7 +219,388 @@ func (r *GenericInfraClusterReconciler) Reconcile(ctx context.Context, req recon
 		}
 	}
 
-	return ctrl.Result{}, r.SetStatusAvailable(ctx)
+	if len(immutableFields) > 0 {
+		err := fmt.Errorf("immutable fields %s of infrastructure cluster %s differ from the values generated from the infrastructure and cannot be changed",
+			strings.Join(immutableFields, ", "), infraClusterCopy.GetName())
+		log.Error(err, "infrastructure cluster is out of sync with the infrastructure")
+		if err := r.SetControllerDegraded(ctx, infraClusterControllerName, err); err != nil {
+			return ctrl.Result{}, fmt.Errorf("error syncing ClusterOperatorStatus: %v", err)
+		}
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, r.SetControllerAvailable(ctx, infraClusterControllerName)
+}
+
+// createInfraCluster creates the InfraCluster for the cluster Infrastructure. It returns nil when
+// the request does not refer to the InfraCluster of this cluster.
+func (r *GenericInfraClusterReconciler) createInfraCluster(ctx context.Context, req reconcile.Request) (client.Object, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	infra := &configv1.Infrastructure{}
+	if err := r.Client.Get(ctx, client.ObjectKey{Name: controllers.InfrastructureResourceName}, infra); err != nil {
+		return nil, fmt.Errorf("unable to get infrastructure: %v", err)
+	}
+
+	if infra.Status.InfrastructureName != req.Name || r.ManagedNamespace != req.Namespace {
+		return nil, nil
+	}
+
+	infraCluster, dependencies, err := r.NewInfraCluster(ctx, r.Client, infra, req.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("unable to generate infra cluster: %w", err)
+	}
+	infraCluster.SetName(req.Name)
+	infraCluster.SetNamespace(req.Namespace)
+
+	generated, err := generatedSpec(infraCluster)
+	if err != nil {
+		return nil, err
+	}
+	if err := setLastAppliedSpec(infraCluster, generated); err != nil {
+		return nil, err
+	}
+
+	if r.InitInfraCluster != nil {
+		if err := r.InitInfraCluster(ctx, r.Client, infra, infraCluster); err != nil {
+			return nil, fmt.Errorf("unable to initialize infra cluster: %w", err)
+		}
+	}
+
+	for _, obj := range dependencies {
+		if err := r.Client.Create(ctx, obj); err != nil && !errors.IsAlreadyExists(err) {
+			return nil, fmt.Errorf("unable to create infra cluster dependency %s: %v", obj.GetName(), err)
+		}
+	}
+
+	log.Info("Creating infrastructure cluster")
+	if err := r.Client.Create(ctx, infraCluster); err != nil {
+		return nil, fmt.Errorf("unable to create infra cluster: %v", err)
+	}
+	r.Recorder.Eventf(infraCluster, corev1.EventTypeNormal, infraClusterCreatedReason, "Created infrastructure cluster %s", infraCluster.GetName())
+
+	return infraCluster, nil
+}
+
+// syncInfraClusterSpec sets the spec fields generated from the Infrastructure on the existing InfraCluster
+// and returns the sorted names of the modified top level spec fields it reverted, followed by the immutable fields
+// that differ from the generated values. A field still holding the last applied value is updated without being
+// reported, as it changed with the Infrastructure. Without an InfraCluster builder only the control plane endpoint
+// is generated. Fields that are not generated, like the ones defaulted by the provider, are kept.
+func (r *GenericInfraClusterReconciler) syncInfraClusterSpec(ctx context.Context, infraCluster client.Object) ([]string, []string, error) {
+	infra := &configv1.Infrastructure{}
+	if err := r.Client.Get(ctx, client.ObjectKey{Name: controllers.InfrastructureResourceName}, infra); errors.IsNotFound(err) {
+		return nil, nil, nil
+	} else if err != nil {
+		return nil, nil, fmt.Errorf("unable to get infrastructure: %v", err)
+	}
+
+	if infra.Status.InfrastructureName != infraCluster.GetName() {
+		return nil, nil, nil
+	}
+
+	desiredSpec, err := r.desiredInfraClusterSpec(ctx, infra, infraCluster.GetNamespace())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	currentUnstructured, err := runtime.DefaultUnstructuredConverter.ToUnstructured(infraCluster)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to convert to unstructured: %v", err)
+	}
+
+	currentSpec, _, err := unstructured.NestedMap(currentUnstructured, "spec")
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to get current spec: %w", err)
+	}
+	originalSpec := runtime.DeepCopyJSON(currentSpec)
+	lastApplied := lastAppliedSpec(infraCluster)
+	applied := runtime.DeepCopyJSON(desiredSpec)
+
+	// Immutable fields are left out of the merge once set, as the provider rejects changing them
+	immutable := []string{}
+	for _, field := range r.ImmutableFields {
+		desiredValue, ok := desiredSpec[field]
+		if !ok || isEmptyField(originalSpec[field]) {
+			continue
+		}
+		delete(desiredSpec, field)
+
+		if !equality.Semantic.DeepEqual(originalSpec[field], mergeFields(runtime.DeepCopyJSONValue(originalSpec[field]), desiredValue)) {
+			immutable = append(immutable, field)
+		}
+	}
+	sort.Strings(immutable)
+
+	mergedSpec := mergeFields(currentSpec, desiredSpec).(map[string]interface{})
+	if err := unstructured.SetNestedMap(currentUnstructured, mergedSpec, "spec"); err != nil {
+		return nil, nil, fmt.Errorf("unable to set spec: %w", err)
+	}
+
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(currentUnstructured, infraCluster); err != nil {
+		return nil, nil, fmt.Errorf("unable to convert from unstructured: %v", err)
+	}
+
+	if err := setLastAppliedSpec(infraCluster, applied); err != nil {
+		return nil, nil, err
+	}
+
+	reverted := []string{}
+	for field := range desiredSpec {
+		if equality.Semantic.DeepEqual(originalSpec[field], mergedSpec[field]) {
+			continue
+		}
+
+		lastValue, ok := lastApplied[field]
+		if ok && equality.Semantic.DeepEqual(originalSpec[field], mergeFields(runtime.DeepCopyJSONValue(originalSpec[field]), lastValue)) {
+			continue
+		}
+
+		// Setting a field that was never generated nor set, like the endpoint once the API server URL is known
+		if !ok && isEmptyField(originalSpec[field]) {
+			continue
+		}
+		reverted = append(reverted, field)
+	}
+	sort.Strings(reverted)
+
+	return reverted, immutable, nil
+}
+
+// desiredInfraClusterSpec returns the InfraCluster spec generated from the Infrastructure as an unstructured map.
+func (r *GenericInfraClusterReconciler) desiredInfraClusterSpec(ctx context.Context, infra *configv1.Infrastructure, namespace string) (map[string]interface{}, error) {
+	if r.NewInfraCluster == nil {
+		// Every InfraCluster has a control plane endpoint, as required by the Cluster API contract
+		endpoint, err := controlPlaneEndpoint(infra)
+		if err != nil {
+			return nil, err
+		}
+
+		if endpoint.IsZero() {
+			return map[string]interface{}{}, nil
+		}
+
+		return map[string]interface{}{
+			"controlPlaneEndpoint": map[string]interface{}{
+				"host": endpoint.Host,
+				"port": int64(endpoint.Port),
+			},
+		}, nil
+	}
+
+	desired, _, err := r.NewInfraCluster(ctx, r.Client, infra, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("unable to generate infra cluster: %v", err)
+	}
+
+	return generatedSpec(desired)
+}
+
+// generatedSpec returns the spec of an InfraCluster generated from the Infrastructure as an unstructured map.
+// The control plane endpoint is left out until the API server URL is known, so the current endpoint is kept.
+func generatedSpec(infraCluster client.Object) (map[string]interface{}, error) {
+	generatedUnstructured, err := runtime.DefaultUnstructuredConverter.ToUnstructured(infraCluster)
+	if err != nil {
+		return nil, fmt.Errorf("unable to convert to unstructured: %v", err)
+	}
+
+	spec, _, err := unstructured.NestedMap(generatedUnstructured, "spec")
+	if err != nil {
+		return nil, fmt.Errorf("unable to get generated spec: %w", err)
+	}
+
+	if endpoint, _, _ := unstructured.NestedMap(spec, "controlPlaneEndpoint"); endpoint["host"] == nil || endpoint["host"] == "" {
+		delete(spec, "controlPlaneEndpoint")
+	}
+
+	return spec, nil
+}
+
+// lastAppliedSpec returns the generated spec last applied to the InfraCluster. It returns nil when the
+// InfraCluster was not synced by the operator yet, in which case every difference is a modification.
+func lastAppliedSpec(infraCluster client.Object) map[string]interface{} {
+	value, ok := infraCluster.GetAnnotations()[lastAppliedSpecAnnotation]
+	if !ok {
+		return nil
+	}
+
+	spec := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(value), &spec); err != nil {
+		return nil
+	}
+
+	return spec
+}
+
+// setLastAppliedSpec records the generated spec applied to the InfraCluster.
+func setLastAppliedSpec(infraCluster client.Object, spec map[string]interface{}) error {
+	value, err := json.Marshal(spec)
+	if err != nil {
+		return fmt.Errorf("unable to marshal last applied spec: %v", err)
+	}
+
+	annotations := infraCluster.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[lastAppliedSpecAnnotation] = string(value)
+	infraCluster.SetAnnotations(annotations)
+
+	return nil
+}
+
+// specRevertedMessage describes the reverted fields of the InfraCluster.
+func specRevertedMessage(name string, reverted []string) string {
+	return fmt.Sprintf("Reverted %s of infrastructure cluster %s to the values generated from the infrastructure",
+		strings.Join(reverted, ", "), name)
+}
+
+// setSpecRevertedCondition records a revert of the InfraCluster spec on the ClusterOperator, next to the event on
+// the InfraCluster whose conditions are owned by the infrastructure provider. The condition is set back to False
+// once a reconcile finds no modification to revert.
+func (r *GenericInfraClusterReconciler) setSpecRevertedCondition(ctx context.Context, name string, reverted []string) error {
+	co, err := r.GetOrCreateClusterOperator(ctx)
+	if err != nil {
+		return err
+	}
+
+	existing := v1helpers.FindStatusCondition(co.Status.Conditions, infraClusterSpecRevertedCondition)
+	cond := operatorstatus.NewClusterOperatorStatusCondition(infraClusterSpecRevertedCondition, configv1.ConditionFalse,
+		operatorstatus.ReasonAsExpected, fmt.Sprintf("Infrastructure cluster %s matches the values generated from the infrastructure", name))
+	if len(reverted) > 0 {
+		cond = operatorstatus.NewClusterOperatorStatusCondition(infraClusterSpecRevertedCondition, configv1.ConditionTrue,
+			infraClusterSpecCorrectedReason, specRevertedMessage(name, reverted))
+	}
+
+	// Nothing was ever reverted, or the condition is up to date
+	if (existing == nil && len(reverted) == 0) ||
+		(existing != nil && existing.Status == cond.Status && existing.Message == cond.Message) {
+		return nil
+	}
+
+	return r.SyncStatus(ctx, co, []configv1.ClusterOperatorStatusCondition{cond})
+}
+
+// mergeFields returns current with the fields set in desired. Maps are merged recursively, and lists
+// of the same length are merged element-wise. Empty desired fields are skipped, so fields defaulted
+// on the current object are kept.
+func mergeFields(current, desired interface{}) interface{} {
+	switch desiredValue := desired.(type) {
+	case map[string]interface{}:
+		currentValue, ok := current.(map[string]interface{})
+		if !ok {
+			return desiredValue
+		}
+		for key, value := range desiredValue {
+			if value == nil || value == "" {
+				continue
+			}
+			currentValue[key] = mergeFields(currentValue[key], value)
+		}
+		return currentValue
+	case []interface{}:
+		currentValue, ok := current.([]interface{})
+		if !ok || len(currentValue) != len(desiredValue) {
+			return desiredValue
+		}
+		for i := range desiredValue {
+			currentValue[i] = mergeFields(currentValue[i], desiredValue[i])
+		}
+		return currentValue
+	default:
+		return desiredValue
+	}
+}
+
+// isEmptyField reports whether an unstructured field is unset, which is the case when all of its values are empty.
+func isEmptyField(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case int64:
+		return v == 0
+	case float64:
+		return v == 0
+	case bool:
+		return !v
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		for _, fieldValue := range v {
+			if !isEmptyField(fieldValue) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// toInfraCluster maps the cluster Infrastructure to the InfraCluster named after its infrastructure name.
+func (r *GenericInfraClusterReconciler) toInfraCluster(obj client.Object) []reconcile.Request {
+	infra, ok := obj.(*configv1.Infrastructure)
+	if !ok || infra.Status.InfrastructureName == "" {
+		return nil
+	}
+
+	return []reconcile.Request{{
+		NamespacedName: client.ObjectKey{Namespace: r.ManagedNamespace, Name: infra.Status.InfrastructureName},
+	}}
+}
+
+// providerOverridesToInfraCluster maps the provider overrides ConfigMap to the InfraCluster of the cluster Infrastructure.
+func (r *GenericInfraClusterReconciler) providerOverridesToInfraCluster(client.Object) []reconcile.Request {
+	infra := &configv1.Infrastructure{}
+	if err := r.Client.Get(context.Background(), client.ObjectKey{Name: controllers.InfrastructureResourceName}, infra); err != nil {
+		return nil
+	}
+
+	return r.toInfraCluster(infra)
+}
+
+func providerOverridesPredicates(namespace string) predicate.Funcs {
+	return predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return util.IsProviderOverridesConfigMap(obj, namespace)
+	})
+}
+
+func infrastructurePredicates() predicate.Funcs {
+	return predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return obj.GetName() == controllers.InfrastructureResourceName
+	})
+}
+
+// controlPlaneEndpoint returns the control plane endpoint from the internal API server URL of the Infrastructure.
+// The endpoint is empty while the URL is not populated yet.
+func controlPlaneEndpoint(infra *configv1.Infrastructure) (clusterv1.APIEndpoint, error) {
+	if infra.Status.APIServerInternalURL == "" {
+		return clusterv1.APIEndpoint{}, nil
+	}
+
+	apiURL, err := url.Parse(infra.Status.APIServerInternalURL)
+	if err != nil {
+		return clusterv1.APIEndpoint{}, fmt.Errorf("unable to parse API server internal URL: %v", err)
+	}
+
+	if apiURL.Hostname() == "" {
+		return clusterv1.APIEndpoint{}, fmt.Errorf("API server internal URL %q has no host", infra.Status.APIServerInternalURL)
+	}
+
+	port := uint64(defaultAPIServerPort)
+	if apiURL.Port() != "" {
+		port, err = strconv.ParseUint(apiURL.Port(), 10, 16)
+		if err != nil {
+			return clusterv1.APIEndpoint{}, fmt.Errorf("unable to parse API server port: %v", err)
+		}
+	}
+
+	return clusterv1.APIEndpoint{
+		Host: apiURL.Hostname(),
+		Port: int32(port),
+	}, nil
 }
 
 func setManagedByAnnotation(annotations map[string]string) map[string]string {
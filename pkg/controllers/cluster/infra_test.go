@@ -3,16 +3,25 @@ package cluster
 import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
 	awsv1 "sigs.k8s.io/cluster-api-provider-aws/v2/api/v1beta1"
+	azurev1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
+	gcpv1 "sigs.k8s.io/cluster-api-provider-gcp/api/v1beta1"
+	ibmcloudv1 "sigs.k8s.io/cluster-api-provider-ibmcloud/api/v1beta1"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
+	configv1 "github.com/openshift/api/config/v1"
 	"github.com/openshift/cluster-capi-operator/pkg/controllers"
 	"github.com/openshift/cluster-capi-operator/pkg/operatorstatus"
 	"github.com/openshift/cluster-capi-operator/pkg/test"
+	"github.com/openshift/library-go/pkg/config/clusteroperator/v1helpers"
 )
 
 var _ = Describe("Reconcile Infrastructure cluster", func() {
@@ -36,9 +45,11 @@ var _ = Describe("Reconcile Infrastructure cluster", func() {
 	It("set annotation and update aws cluster status", func() {
 		r := &GenericInfraClusterReconciler{
 			ClusterOperatorStatusClient: operatorstatus.ClusterOperatorStatusClient{
-				Client: cl,
+				Client:       cl,
+				PlatformType: configv1.AWSPlatformType,
 			},
-			InfraCluster: &awsv1.AWSCluster{},
+			InfraCluster:       &awsv1.AWSCluster{},
+			SupportedPlatforms: map[string]bool{"aws": true},
 		}
 
 		_, err := r.Reconcile(ctx, reconcile.Request{
@@ -58,3 +69,953 @@ var _ = Describe("Reconcile Infrastructure cluster", func() {
 		Expect(awsCluster.Status.Ready).To(BeTrue())
 	})
 })
+
+var _ = Describe("Create AWS infrastructure cluster", func() {
+	var infra *configv1.Infrastructure
+	var r *GenericInfraClusterReconciler
+	var rec *record.FakeRecorder
+
+	infraClusterKey := client.ObjectKey{Name: "test-infra-name", Namespace: controllers.DefaultManagedNamespace}
+
+	BeforeEach(func() {
+		infra = &configv1.Infrastructure{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: controllers.InfrastructureResourceName,
+			},
+		}
+		Expect(cl.Create(ctx, infra)).To(Succeed())
+
+		infra.Status = configv1.InfrastructureStatus{
+			InfrastructureName:   infraClusterKey.Name,
+			APIServerInternalURL: "https://api-int.test.example.com:6443",
+			PlatformStatus: &configv1.PlatformStatus{
+				Type: configv1.AWSPlatformType,
+				AWS: &configv1.AWSPlatformStatus{
+					Region: "eu-west-1",
+					ResourceTags: []configv1.AWSResourceTag{
+						{Key: "team", Value: "capi"},
+					},
+				},
+			},
+		}
+		Expect(cl.Status().Update(ctx, infra)).To(Succeed())
+
+		rec = record.NewFakeRecorder(32)
+		r = &GenericInfraClusterReconciler{
+			ClusterOperatorStatusClient: operatorstatus.ClusterOperatorStatusClient{
+				Client:           cl,
+				Recorder:         rec,
+				ManagedNamespace: controllers.DefaultManagedNamespace,
+				PlatformType:     configv1.AWSPlatformType,
+			},
+			InfraCluster:       &awsv1.AWSCluster{},
+			NewInfraCluster:    NewAWSCluster,
+			ImmutableFields:    AWSClusterImmutableFields,
+			SupportedPlatforms: map[string]bool{"aws": true},
+		}
+	})
+
+	AfterEach(func() {
+		awsCluster := &awsv1.AWSCluster{}
+		awsCluster.SetName(infraClusterKey.Name)
+		awsCluster.SetNamespace(infraClusterKey.Namespace)
+		co := &configv1.ClusterOperator{}
+		co.SetName(controllers.ClusterOperatorName)
+		Expect(test.CleanupAndWait(ctx, cl, awsCluster, infra, co)).To(Succeed())
+	})
+
+	It("should create an externally managed AWSCluster from the infrastructure", func() {
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: infraClusterKey})
+		Expect(err).ToNot(HaveOccurred())
+
+		awsCluster := &awsv1.AWSCluster{}
+		Expect(cl.Get(ctx, infraClusterKey, awsCluster)).To(Succeed())
+		Expect(awsCluster.Annotations).To(HaveKey(clusterv1.ManagedByAnnotation))
+		Expect(awsCluster.Spec.Region).To(Equal("eu-west-1"))
+		Expect(awsCluster.Spec.AdditionalTags).To(Equal(awsv1.Tags{"team": "capi"}))
+		Expect(awsCluster.Spec.ControlPlaneEndpoint).To(Equal(clusterv1.APIEndpoint{Host: "api-int.test.example.com", Port: 6443}))
+		Expect(awsCluster.Status.Ready).To(BeTrue())
+		Expect(rec.Events).To(Receive(ContainSubstring(infraClusterCreatedReason)))
+		Expect(rec.Events).NotTo(Receive())
+	})
+
+	It("should keep the ClusterOperator degraded by a failing component", func() {
+		Expect(r.SetStatusFromComponents(ctx, []operatorstatus.ComponentStatus{
+			{Name: "Deployment/capa-controller-manager", Degraded: true, Message: "container manager is in CrashLoopBackOff"},
+		})).To(Succeed())
+
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: infraClusterKey})
+		Expect(err).ToNot(HaveOccurred())
+
+		co := &configv1.ClusterOperator{}
+		Expect(cl.Get(ctx, client.ObjectKey{Name: controllers.ClusterOperatorName}, co)).To(Succeed())
+		Expect(v1helpers.IsStatusConditionTrue(co.Status.Conditions, configv1.OperatorDegraded)).To(BeTrue())
+		Expect(v1helpers.IsStatusConditionTrue(co.Status.Conditions, infraClusterControllerName+"Available")).To(BeTrue())
+	})
+
+	It("should revert manual modifications of the generated fields", func() {
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: infraClusterKey})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rec.Events).To(Receive(ContainSubstring(infraClusterCreatedReason)))
+
+		awsCluster := &awsv1.AWSCluster{}
+		Expect(cl.Get(ctx, infraClusterKey, awsCluster)).To(Succeed())
+		awsCluster.Spec.AdditionalTags = awsv1.Tags{"team": "other"}
+		awsCluster.Spec.SSHKeyName = pointer.String("debug")
+		Expect(cl.Update(ctx, awsCluster)).To(Succeed())
+
+		_, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: infraClusterKey})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(cl.Get(ctx, infraClusterKey, awsCluster)).To(Succeed())
+		Expect(awsCluster.Spec.AdditionalTags).To(Equal(awsv1.Tags{"team": "capi"}))
+		Expect(awsCluster.Spec.SSHKeyName).To(HaveValue(Equal("debug")))
+		Expect(rec.Events).To(Receive(And(
+			ContainSubstring(infraClusterSpecCorrectedReason),
+			ContainSubstring("Reverted additionalTags of infrastructure cluster"),
+		)))
+
+		co := &configv1.ClusterOperator{}
+		Expect(cl.Get(ctx, client.ObjectKey{Name: controllers.ClusterOperatorName}, co)).To(Succeed())
+		Expect(v1helpers.IsStatusConditionTrue(co.Status.Conditions, infraClusterSpecRevertedCondition)).To(BeTrue())
+
+		By("Reconciling again without modifications")
+		_, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: infraClusterKey})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(cl.Get(ctx, client.ObjectKey{Name: controllers.ClusterOperatorName}, co)).To(Succeed())
+		Expect(v1helpers.IsStatusConditionFalse(co.Status.Conditions, infraClusterSpecRevertedCondition)).To(BeTrue())
+		Expect(rec.Events).NotTo(Receive())
+	})
+
+	It("should not report infrastructure driven updates as reverted", func() {
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: infraClusterKey})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rec.Events).To(Receive(ContainSubstring(infraClusterCreatedReason)))
+
+		awsCluster := &awsv1.AWSCluster{}
+		Expect(cl.Get(ctx, infraClusterKey, awsCluster)).To(Succeed())
+		Expect(awsCluster.Annotations).To(HaveKey(lastAppliedSpecAnnotation))
+
+		infra.Status.PlatformStatus.AWS.ResourceTags = []configv1.AWSResourceTag{{Key: "team", Value: "infra"}}
+		Expect(cl.Status().Update(ctx, infra)).To(Succeed())
+
+		_, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: infraClusterKey})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(cl.Get(ctx, infraClusterKey, awsCluster)).To(Succeed())
+		Expect(awsCluster.Spec.AdditionalTags).To(Equal(awsv1.Tags{"team": "infra"}))
+		Expect(rec.Events).NotTo(Receive())
+
+		co := &configv1.ClusterOperator{}
+		Expect(cl.Get(ctx, client.ObjectKey{Name: controllers.ClusterOperatorName}, co)).To(Succeed())
+		Expect(v1helpers.FindStatusCondition(co.Status.Conditions, infraClusterSpecRevertedCondition)).To(BeNil())
+	})
+
+	It("should report changes of immutable fields instead of reverting them", func() {
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: infraClusterKey})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rec.Events).To(Receive(ContainSubstring(infraClusterCreatedReason)))
+
+		awsCluster := &awsv1.AWSCluster{}
+		Expect(cl.Get(ctx, infraClusterKey, awsCluster)).To(Succeed())
+		awsCluster.Spec.Region = "us-west-2"
+		Expect(cl.Update(ctx, awsCluster)).To(Succeed())
+
+		_, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: infraClusterKey})
+		Expect(err).To(MatchError(ContainSubstring("region of infrastructure cluster test-infra-name differ")))
+
+		Expect(cl.Get(ctx, infraClusterKey, awsCluster)).To(Succeed())
+		Expect(awsCluster.Spec.Region).To(Equal("us-west-2"))
+
+		co := &configv1.ClusterOperator{}
+		Expect(cl.Get(ctx, client.ObjectKey{Name: controllers.ClusterOperatorName}, co)).To(Succeed())
+		Expect(v1helpers.IsStatusConditionTrue(co.Status.Conditions, infraClusterControllerName+"Degraded")).To(BeTrue())
+	})
+
+	It("should patch a missing control plane endpoint once the API server URL is known", func() {
+		infra.Status.APIServerInternalURL = ""
+		Expect(cl.Status().Update(ctx, infra)).To(Succeed())
+
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: infraClusterKey})
+		Expect(err).ToNot(HaveOccurred())
+
+		awsCluster := &awsv1.AWSCluster{}
+		Expect(cl.Get(ctx, infraClusterKey, awsCluster)).To(Succeed())
+		Expect(awsCluster.Spec.ControlPlaneEndpoint.IsZero()).To(BeTrue())
+
+		infra.Status.APIServerInternalURL = "https://api-int.test.example.com:6443"
+		Expect(cl.Status().Update(ctx, infra)).To(Succeed())
+
+		_, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: infraClusterKey})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(cl.Get(ctx, infraClusterKey, awsCluster)).To(Succeed())
+		Expect(awsCluster.Spec.ControlPlaneEndpoint).To(Equal(clusterv1.APIEndpoint{Host: "api-int.test.example.com", Port: 6443}))
+		Expect(rec.Events).To(Receive(ContainSubstring(infraClusterCreatedReason)))
+		Expect(rec.Events).NotTo(Receive())
+	})
+
+	It("should report a stale control plane endpoint instead of patching it", func() {
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: infraClusterKey})
+		Expect(err).ToNot(HaveOccurred())
+
+		infra.Status.APIServerInternalURL = "https://api-int.new.example.com:6443"
+		Expect(cl.Status().Update(ctx, infra)).To(Succeed())
+
+		_, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: infraClusterKey})
+		Expect(err).To(MatchError(ContainSubstring("controlPlaneEndpoint of infrastructure cluster")))
+
+		awsCluster := &awsv1.AWSCluster{}
+		Expect(cl.Get(ctx, infraClusterKey, awsCluster)).To(Succeed())
+		Expect(awsCluster.Spec.ControlPlaneEndpoint).To(Equal(clusterv1.APIEndpoint{Host: "api-int.test.example.com", Port: 6443}))
+	})
+
+	It("should recreate a deleted AWSCluster", func() {
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: infraClusterKey})
+		Expect(err).ToNot(HaveOccurred())
+
+		awsCluster := &awsv1.AWSCluster{}
+		Expect(cl.Get(ctx, infraClusterKey, awsCluster)).To(Succeed())
+		originalUID := awsCluster.UID
+		Expect(test.CleanupAndWait(ctx, cl, awsCluster)).To(Succeed())
+
+		_, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: infraClusterKey})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(cl.Get(ctx, infraClusterKey, awsCluster)).To(Succeed())
+		Expect(awsCluster.UID).NotTo(Equal(originalUID))
+		Expect(awsCluster.Spec.Region).To(Equal("eu-west-1"))
+	})
+})
+
+var _ = Describe("AWS infrastructure cluster builder", func() {
+	It("should fail without an AWS platform status", func() {
+		_, _, err := NewAWSCluster(ctx, nil, &configv1.Infrastructure{
+			Status: configv1.InfrastructureStatus{
+				PlatformStatus: &configv1.PlatformStatus{Type: configv1.AWSPlatformType},
+			},
+		}, controllers.DefaultManagedNamespace)
+		Expect(err).To(MatchError("infrastructure has no AWS platform status"))
+	})
+
+	It("should not set additional tags without resource tags", func() {
+		obj, _, err := NewAWSCluster(ctx, nil, &configv1.Infrastructure{
+			Status: configv1.InfrastructureStatus{
+				InfrastructureName: "test-infra-name",
+				PlatformStatus: &configv1.PlatformStatus{
+					Type: configv1.AWSPlatformType,
+					AWS:  &configv1.AWSPlatformStatus{Region: "us-east-1"},
+				},
+			},
+		}, controllers.DefaultManagedNamespace)
+		Expect(err).NotTo(HaveOccurred())
+
+		awsCluster, ok := obj.(*awsv1.AWSCluster)
+		Expect(ok).To(BeTrue())
+		Expect(awsCluster.Spec.Region).To(Equal("us-east-1"))
+		Expect(awsCluster.Spec.AdditionalTags).To(BeNil())
+		Expect(awsCluster.Spec.ControlPlaneEndpoint.IsZero()).To(BeTrue())
+	})
+})
+
+var _ = Describe("Create IBM VPC infrastructure cluster", func() {
+	var infra *configv1.Infrastructure
+	var r *GenericInfraClusterReconciler
+
+	infraClusterKey := client.ObjectKey{Name: "test-infra-name", Namespace: controllers.DefaultManagedNamespace}
+
+	BeforeEach(func() {
+		infra = &configv1.Infrastructure{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: controllers.InfrastructureResourceName,
+			},
+		}
+		Expect(cl.Create(ctx, infra)).To(Succeed())
+
+		infra.Status = configv1.InfrastructureStatus{
+			InfrastructureName:   infraClusterKey.Name,
+			APIServerInternalURL: "https://api-int.test.example.com:6443",
+			PlatformStatus: &configv1.PlatformStatus{
+				Type: configv1.IBMCloudPlatformType,
+				IBMCloud: &configv1.IBMCloudPlatformStatus{
+					Location:          "us-south",
+					ResourceGroupName: "test-resource-group",
+					ProviderType:      configv1.IBMCloudProviderTypeVPC,
+				},
+			},
+		}
+		Expect(cl.Status().Update(ctx, infra)).To(Succeed())
+
+		r = &GenericInfraClusterReconciler{
+			ClusterOperatorStatusClient: operatorstatus.ClusterOperatorStatusClient{
+				Client:           cl,
+				Recorder:         record.NewFakeRecorder(32),
+				ManagedNamespace: controllers.DefaultManagedNamespace,
+				PlatformType:     configv1.IBMCloudPlatformType,
+			},
+			InfraCluster:       &ibmcloudv1.IBMVPCCluster{},
+			NewInfraCluster:    NewIBMVPCCluster,
+			SupportedPlatforms: map[string]bool{"ibmcloud": true},
+		}
+	})
+
+	AfterEach(func() {
+		vpcCluster := &ibmcloudv1.IBMVPCCluster{}
+		vpcCluster.SetName(infraClusterKey.Name)
+		vpcCluster.SetNamespace(infraClusterKey.Namespace)
+		co := &configv1.ClusterOperator{}
+		co.SetName(controllers.ClusterOperatorName)
+		Expect(test.CleanupAndWait(ctx, cl, vpcCluster, infra, co)).To(Succeed())
+	})
+
+	It("should create an externally managed IBMVPCCluster from the infrastructure", func() {
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: infraClusterKey})
+		Expect(err).ToNot(HaveOccurred())
+
+		vpcCluster := &ibmcloudv1.IBMVPCCluster{}
+		Expect(cl.Get(ctx, infraClusterKey, vpcCluster)).To(Succeed())
+		Expect(vpcCluster.Annotations).To(HaveKey(clusterv1.ManagedByAnnotation))
+		Expect(vpcCluster.Spec.Region).To(Equal("us-south"))
+		Expect(vpcCluster.Spec.ResourceGroup).To(Equal("test-resource-group"))
+		Expect(vpcCluster.Spec.ControlPlaneEndpoint).To(Equal(clusterv1.APIEndpoint{Host: "api-int.test.example.com", Port: 6443}))
+		Expect(vpcCluster.Status.Ready).To(BeTrue())
+	})
+
+	It("should ignore requests for other infrastructure clusters", func() {
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKey{Name: "other", Namespace: infraClusterKey.Namespace}})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(cl.Get(ctx, client.ObjectKey{Name: "other", Namespace: infraClusterKey.Namespace}, &ibmcloudv1.IBMVPCCluster{})).NotTo(Succeed())
+	})
+})
+
+var _ = Describe("Control plane endpoint", func() {
+	DescribeTable("should parse the API server internal URL",
+		func(apiURL string, expected clusterv1.APIEndpoint) {
+			endpoint, err := controlPlaneEndpoint(&configv1.Infrastructure{
+				Status: configv1.InfrastructureStatus{APIServerInternalURL: apiURL},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(endpoint).To(Equal(expected))
+		},
+		Entry("with an explicit port", "https://api-int.example.com:6443", clusterv1.APIEndpoint{Host: "api-int.example.com", Port: 6443}),
+		Entry("with a non default port", "https://api-int.example.com:8443", clusterv1.APIEndpoint{Host: "api-int.example.com", Port: 8443}),
+		Entry("without a port", "https://api-int.example.com", clusterv1.APIEndpoint{Host: "api-int.example.com", Port: 6443}),
+		Entry("with an IPv4 host", "https://10.0.0.10:6443", clusterv1.APIEndpoint{Host: "10.0.0.10", Port: 6443}),
+		Entry("with an IPv6 host and a port", "https://[fd00::10]:6443", clusterv1.APIEndpoint{Host: "fd00::10", Port: 6443}),
+		Entry("with an IPv6 host without a port", "https://[fd00::10]", clusterv1.APIEndpoint{Host: "fd00::10", Port: 6443}),
+		Entry("when the URL is not populated yet", "", clusterv1.APIEndpoint{}),
+	)
+
+	DescribeTable("should fail to parse an invalid API server internal URL",
+		func(apiURL string) {
+			_, err := controlPlaneEndpoint(&configv1.Infrastructure{
+				Status: configv1.InfrastructureStatus{APIServerInternalURL: apiURL},
+			})
+			Expect(err).To(HaveOccurred())
+		},
+		Entry("without a host", "https://"),
+		Entry("with an invalid port", "https://api-int.example.com:port"),
+		Entry("with an out of range port", "https://api-int.example.com:70000"),
+	)
+})
+
+var _ = Describe("Merge fields", func() {
+	It("should set desired fields and keep the current ones", func() {
+		current := map[string]interface{}{
+			"region": "us-east-1",
+			"controlPlaneEndpoint": map[string]interface{}{
+				"host": "old.example.com",
+				"port": int64(6443),
+			},
+			"subnets": []interface{}{
+				map[string]interface{}{"name": "subnet", "cidrBlocks": []interface{}{"10.0.0.0/16"}},
+			},
+		}
+		desired := map[string]interface{}{
+			"region": "",
+			"controlPlaneEndpoint": map[string]interface{}{
+				"host": "new.example.com",
+				"port": int64(6443),
+			},
+			"subnets": []interface{}{
+				map[string]interface{}{"name": "subnet"},
+			},
+		}
+
+		Expect(mergeFields(current, desired)).To(Equal(map[string]interface{}{
+			"region": "us-east-1",
+			"controlPlaneEndpoint": map[string]interface{}{
+				"host": "new.example.com",
+				"port": int64(6443),
+			},
+			"subnets": []interface{}{
+				map[string]interface{}{"name": "subnet", "cidrBlocks": []interface{}{"10.0.0.0/16"}},
+			},
+		}))
+	})
+
+	It("should replace lists with a different length", func() {
+		current := map[string]interface{}{"subnets": []interface{}{"a", "b"}}
+		desired := map[string]interface{}{"subnets": []interface{}{"c"}}
+
+		Expect(mergeFields(current, desired)).To(Equal(map[string]interface{}{"subnets": []interface{}{"c"}}))
+	})
+})
+
+var _ = Describe("Reconcile control plane endpoint drift", func() {
+	var infra *configv1.Infrastructure
+	var awsCluster *awsv1.AWSCluster
+
+	BeforeEach(func() {
+		infra = &configv1.Infrastructure{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: controllers.InfrastructureResourceName,
+			},
+		}
+		Expect(cl.Create(ctx, infra)).To(Succeed())
+
+		infra.Status = configv1.InfrastructureStatus{
+			InfrastructureName:   "test-infra-name",
+			APIServerInternalURL: "https://api-int.new.example.com:6443",
+		}
+		Expect(cl.Status().Update(ctx, infra)).To(Succeed())
+
+		awsCluster = &awsv1.AWSCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-infra-name",
+				Namespace: controllers.DefaultManagedNamespace,
+			},
+			Spec: awsv1.AWSClusterSpec{
+				Region: "us-east-1",
+				ControlPlaneEndpoint: clusterv1.APIEndpoint{
+					Host: "api-int.old.example.com",
+					Port: 6443,
+				},
+			},
+		}
+		Expect(cl.Create(ctx, awsCluster)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(test.CleanupAndWait(ctx, cl, awsCluster, infra)).To(Succeed())
+	})
+
+	It("should patch a stale control plane endpoint", func() {
+		rec := record.NewFakeRecorder(32)
+		r := &GenericInfraClusterReconciler{
+			ClusterOperatorStatusClient: operatorstatus.ClusterOperatorStatusClient{
+				Client:           cl,
+				Recorder:         rec,
+				ManagedNamespace: controllers.DefaultManagedNamespace,
+				PlatformType:     configv1.AWSPlatformType,
+			},
+			InfraCluster:       &awsv1.AWSCluster{},
+			SupportedPlatforms: map[string]bool{"aws": true},
+		}
+
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(awsCluster)})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(cl.Get(ctx, client.ObjectKeyFromObject(awsCluster), awsCluster)).To(Succeed())
+		Expect(awsCluster.Spec.ControlPlaneEndpoint).To(Equal(clusterv1.APIEndpoint{Host: "api-int.new.example.com", Port: 6443}))
+		Expect(awsCluster.Spec.Region).To(Equal("us-east-1"))
+		Expect(rec.Events).To(Receive(And(ContainSubstring(infraClusterSpecCorrectedReason), ContainSubstring("controlPlaneEndpoint"))))
+	})
+})
+
+var _ = Describe("Create Azure infrastructure cluster", func() {
+	var infra *configv1.Infrastructure
+	var credentials *corev1.Secret
+	var r *GenericInfraClusterReconciler
+	var rec *record.FakeRecorder
+
+	infraClusterKey := client.ObjectKey{Name: "test-infra-name", Namespace: controllers.DefaultManagedNamespace}
+
+	createInfrastructure := func(cloudName configv1.AzureCloudEnvironment) {
+		infra = &configv1.Infrastructure{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: controllers.InfrastructureResourceName,
+			},
+		}
+		Expect(cl.Create(ctx, infra)).To(Succeed())
+
+		infra.Status = configv1.InfrastructureStatus{
+			InfrastructureName:   infraClusterKey.Name,
+			APIServerInternalURL: "https://api-int.test.example.com:6443",
+			PlatformStatus: &configv1.PlatformStatus{
+				Type: configv1.AzurePlatformType,
+				Azure: &configv1.AzurePlatformStatus{
+					ResourceGroupName:        "test-rg",
+					NetworkResourceGroupName: "test-network-rg",
+					CloudName:                cloudName,
+				},
+			},
+		}
+		Expect(cl.Status().Update(ctx, infra)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		credentials = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      azureCredentialsSecretName,
+				Namespace: controllers.DefaultManagedNamespace,
+			},
+			Data: map[string][]byte{
+				azureClientIDKey:       []byte("test-client-id"),
+				azureTenantIDKey:       []byte("test-tenant-id"),
+				azureRegionKey:         []byte("eastus"),
+				azureSubscriptionIDKey: []byte("test-subscription-id"),
+				"azure_client_secret":  []byte("test-client-secret"),
+			},
+		}
+
+		rec = record.NewFakeRecorder(32)
+		r = &GenericInfraClusterReconciler{
+			ClusterOperatorStatusClient: operatorstatus.ClusterOperatorStatusClient{
+				Client:           cl,
+				Recorder:         rec,
+				ManagedNamespace: controllers.DefaultManagedNamespace,
+				PlatformType:     configv1.AzurePlatformType,
+			},
+			InfraCluster:       &azurev1.AzureCluster{},
+			NewInfraCluster:    NewAzureCluster,
+			ImmutableFields:    AzureClusterImmutableFields,
+			SupportedPlatforms: map[string]bool{"azure": true},
+		}
+	})
+
+	AfterEach(func() {
+		azureCluster := &azurev1.AzureCluster{}
+		azureCluster.SetName(infraClusterKey.Name)
+		azureCluster.SetNamespace(infraClusterKey.Namespace)
+		identity := &azurev1.AzureClusterIdentity{}
+		identity.SetName(infraClusterKey.Name)
+		identity.SetNamespace(infraClusterKey.Namespace)
+		co := &configv1.ClusterOperator{}
+		co.SetName(controllers.ClusterOperatorName)
+		Expect(test.CleanupAndWait(ctx, cl, azureCluster, identity, credentials, infra, co)).To(Succeed())
+	})
+
+	Context("with credentials", func() {
+		BeforeEach(func() {
+			Expect(cl.Create(ctx, credentials)).To(Succeed())
+		})
+
+		It("should create an AzureCluster for the public cloud", func() {
+			createInfrastructure("")
+
+			_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: infraClusterKey})
+			Expect(err).ToNot(HaveOccurred())
+
+			azureCluster := &azurev1.AzureCluster{}
+			Expect(cl.Get(ctx, infraClusterKey, azureCluster)).To(Succeed())
+			Expect(azureCluster.Annotations).To(HaveKey(clusterv1.ManagedByAnnotation))
+			Expect(azureCluster.Spec.ResourceGroup).To(Equal("test-rg"))
+			Expect(azureCluster.Spec.NetworkSpec.Vnet.ResourceGroup).To(Equal("test-network-rg"))
+			Expect(azureCluster.Spec.NetworkSpec.Vnet.Name).To(Equal("test-infra-name-vnet"))
+			Expect(azureCluster.Spec.NetworkSpec.Subnets).To(HaveLen(2))
+			Expect(azureCluster.Spec.Location).To(Equal("eastus"))
+			Expect(azureCluster.Spec.SubscriptionID).To(Equal("test-subscription-id"))
+			Expect(azureCluster.Spec.AzureEnvironment).To(Equal("AzurePublicCloud"))
+			Expect(azureCluster.Spec.ControlPlaneEndpoint).To(Equal(clusterv1.APIEndpoint{Host: "api-int.test.example.com", Port: 6443}))
+			Expect(azureCluster.Spec.IdentityRef).ToNot(BeNil())
+			Expect(azureCluster.Spec.IdentityRef.Name).To(Equal(infraClusterKey.Name))
+			Expect(azureCluster.Status.Ready).To(BeTrue())
+
+			identity := &azurev1.AzureClusterIdentity{}
+			Expect(cl.Get(ctx, infraClusterKey, identity)).To(Succeed())
+			Expect(identity.Spec.ClientID).To(Equal("test-client-id"))
+			Expect(identity.Spec.TenantID).To(Equal("test-tenant-id"))
+			Expect(identity.Spec.ClientSecret.Name).To(Equal(azureCredentialsSecretName))
+		})
+
+		It("should report a resource group change instead of patching it", func() {
+			createInfrastructure("")
+
+			_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: infraClusterKey})
+			Expect(err).ToNot(HaveOccurred())
+
+			infra.Status.PlatformStatus.Azure.ResourceGroupName = "other-rg"
+			Expect(cl.Status().Update(ctx, infra)).To(Succeed())
+
+			_, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: infraClusterKey})
+			Expect(err).To(MatchError(ContainSubstring("resourceGroup of infrastructure cluster")))
+
+			azureCluster := &azurev1.AzureCluster{}
+			Expect(cl.Get(ctx, infraClusterKey, azureCluster)).To(Succeed())
+			Expect(azureCluster.Spec.ResourceGroup).To(Equal("test-rg"))
+		})
+
+		It("should set the Azure environment for the government cloud", func() {
+			createInfrastructure(configv1.AzureUSGovernmentCloud)
+
+			_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: infraClusterKey})
+			Expect(err).ToNot(HaveOccurred())
+
+			azureCluster := &azurev1.AzureCluster{}
+			Expect(cl.Get(ctx, infraClusterKey, azureCluster)).To(Succeed())
+			Expect(azureCluster.Spec.AzureEnvironment).To(Equal("AzureUSGovernmentCloud"))
+		})
+
+		It("should skip the AzureCluster for Azure Stack Hub without degrading", func() {
+			createInfrastructure(configv1.AzureStackCloud)
+
+			_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: infraClusterKey})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(cl.Get(ctx, infraClusterKey, &azurev1.AzureCluster{})).NotTo(Succeed())
+			Expect(rec.Events).To(Receive(And(ContainSubstring(infraClusterNotSupportedReason), ContainSubstring("AzureStackCloud"))))
+
+			co := &configv1.ClusterOperator{}
+			Expect(cl.Get(ctx, client.ObjectKey{Name: controllers.ClusterOperatorName}, co)).To(Succeed())
+			Expect(v1helpers.IsStatusConditionTrue(co.Status.Conditions, infraClusterControllerName+"Degraded")).To(BeFalse())
+		})
+	})
+
+	It("should report missing credentials in the ClusterOperator status", func() {
+		createInfrastructure("")
+
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: infraClusterKey})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring(azureCredentialsSecretName))
+
+		Expect(cl.Get(ctx, infraClusterKey, &azurev1.AzureCluster{})).NotTo(Succeed())
+
+		co := &configv1.ClusterOperator{}
+		Expect(cl.Get(ctx, client.ObjectKey{Name: controllers.ClusterOperatorName}, co)).To(Succeed())
+		Expect(v1helpers.IsStatusConditionTrue(co.Status.Conditions, infraClusterControllerName+"Degraded")).To(BeTrue())
+	})
+})
+
+var _ = Describe("Create GCP infrastructure cluster", func() {
+	var infra *configv1.Infrastructure
+	var r *GenericInfraClusterReconciler
+	var rec *record.FakeRecorder
+
+	infraClusterKey := client.ObjectKey{Name: "test-infra-name", Namespace: controllers.DefaultManagedNamespace}
+
+	BeforeEach(func() {
+		infra = &configv1.Infrastructure{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: controllers.InfrastructureResourceName,
+			},
+		}
+		Expect(cl.Create(ctx, infra)).To(Succeed())
+
+		infra.Status = configv1.InfrastructureStatus{
+			InfrastructureName:   infraClusterKey.Name,
+			APIServerInternalURL: "https://api-int.test.example.com:6443",
+			PlatformStatus: &configv1.PlatformStatus{
+				Type: configv1.GCPPlatformType,
+				GCP: &configv1.GCPPlatformStatus{
+					ProjectID: "test-project",
+					Region:    "us-central1",
+				},
+			},
+		}
+		Expect(cl.Status().Update(ctx, infra)).To(Succeed())
+
+		rec = record.NewFakeRecorder(32)
+		r = &GenericInfraClusterReconciler{
+			ClusterOperatorStatusClient: operatorstatus.ClusterOperatorStatusClient{
+				Client:           cl,
+				Recorder:         rec,
+				ManagedNamespace: controllers.DefaultManagedNamespace,
+				PlatformType:     configv1.GCPPlatformType,
+			},
+			InfraCluster:       &gcpv1.GCPCluster{},
+			NewInfraCluster:    NewGCPCluster,
+			InitInfraCluster:   InitGCPCluster,
+			ImmutableFields:    GCPClusterImmutableFields,
+			SupportedPlatforms: map[string]bool{"gcp": true},
+		}
+	})
+
+	AfterEach(func() {
+		gcpCluster := &gcpv1.GCPCluster{}
+		gcpCluster.SetName(infraClusterKey.Name)
+		gcpCluster.SetNamespace(infraClusterKey.Namespace)
+		co := &configv1.ClusterOperator{}
+		co.SetName(controllers.ClusterOperatorName)
+		Expect(test.CleanupAndWait(ctx, cl, gcpCluster, infra, co)).To(Succeed())
+	})
+
+	It("should create an externally managed GCPCluster from the infrastructure", func() {
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: infraClusterKey})
+		Expect(err).ToNot(HaveOccurred())
+
+		gcpCluster := &gcpv1.GCPCluster{}
+		Expect(cl.Get(ctx, infraClusterKey, gcpCluster)).To(Succeed())
+		Expect(gcpCluster.Annotations).To(HaveKey(clusterv1.ManagedByAnnotation))
+		Expect(gcpCluster.Spec.Project).To(Equal("test-project"))
+		Expect(gcpCluster.Spec.Region).To(Equal("us-central1"))
+		Expect(gcpCluster.Spec.Network.Name).To(HaveValue(Equal("test-infra-name-network")))
+		Expect(gcpCluster.Spec.ControlPlaneEndpoint).To(Equal(clusterv1.APIEndpoint{Host: "api-int.test.example.com", Port: 6443}))
+		Expect(gcpCluster.Status.Ready).To(BeTrue())
+	})
+
+	It("should not modify the GCPCluster on subsequent reconciles", func() {
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: infraClusterKey})
+		Expect(err).ToNot(HaveOccurred())
+
+		gcpCluster := &gcpv1.GCPCluster{}
+		Expect(cl.Get(ctx, infraClusterKey, gcpCluster)).To(Succeed())
+		resourceVersion := gcpCluster.ResourceVersion
+
+		_, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: infraClusterKey})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(cl.Get(ctx, infraClusterKey, gcpCluster)).To(Succeed())
+		Expect(gcpCluster.ResourceVersion).To(Equal(resourceVersion))
+	})
+
+	It("should reconcile infrastructure changes into the existing GCPCluster", func() {
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: infraClusterKey})
+		Expect(err).ToNot(HaveOccurred())
+
+		infra.Status.APIServerInternalURL = "https://api-int.test.example.com:7443"
+		Expect(cl.Status().Update(ctx, infra)).To(Succeed())
+
+		_, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: infraClusterKey})
+		Expect(err).ToNot(HaveOccurred())
+
+		gcpCluster := &gcpv1.GCPCluster{}
+		Expect(cl.Get(ctx, infraClusterKey, gcpCluster)).To(Succeed())
+		Expect(gcpCluster.Spec.ControlPlaneEndpoint).To(Equal(clusterv1.APIEndpoint{Host: "api-int.test.example.com", Port: 7443}))
+		Expect(gcpCluster.Spec.Region).To(Equal("us-central1"))
+		Expect(gcpCluster.Spec.Project).To(Equal("test-project"))
+	})
+
+	It("should report a region change instead of patching it", func() {
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: infraClusterKey})
+		Expect(err).ToNot(HaveOccurred())
+
+		infra.Status.PlatformStatus.GCP.Region = "europe-west1"
+		Expect(cl.Status().Update(ctx, infra)).To(Succeed())
+
+		_, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: infraClusterKey})
+		Expect(err).To(MatchError(ContainSubstring("region of infrastructure cluster test-infra-name differ")))
+
+		gcpCluster := &gcpv1.GCPCluster{}
+		Expect(cl.Get(ctx, infraClusterKey, gcpCluster)).To(Succeed())
+		Expect(gcpCluster.Spec.Region).To(Equal("us-central1"))
+
+		co := &configv1.ClusterOperator{}
+		Expect(cl.Get(ctx, client.ObjectKey{Name: controllers.ClusterOperatorName}, co)).To(Succeed())
+		Expect(v1helpers.IsStatusConditionTrue(co.Status.Conditions, infraClusterControllerName+"Degraded")).To(BeTrue())
+	})
+
+	Context("with MachineSets", func() {
+		var machineSet *unstructured.Unstructured
+
+		createMachineSet := func(network, projectID string) {
+			machineSet = &unstructured.Unstructured{}
+			machineSet.SetAPIVersion("machine.openshift.io/v1beta1")
+			machineSet.SetKind("MachineSet")
+			machineSet.SetName("test-infra-name-worker-a")
+			machineSet.SetNamespace(controllers.MachineAPINamespace)
+			Expect(unstructured.SetNestedSlice(machineSet.Object, []interface{}{
+				map[string]interface{}{
+					"network":    network,
+					"projectID":  projectID,
+					"subnetwork": "worker-subnet",
+				},
+			}, "spec", "template", "spec", "providerSpec", "value", "networkInterfaces")).To(Succeed())
+			Expect(cl.Create(ctx, machineSet)).To(Succeed())
+		}
+
+		BeforeEach(func() {
+			machineAPINamespace := &corev1.Namespace{}
+			machineAPINamespace.SetName(controllers.MachineAPINamespace)
+			Expect(client.IgnoreAlreadyExists(cl.Create(ctx, machineAPINamespace))).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(test.CleanupAndWait(ctx, cl, machineSet)).To(Succeed())
+		})
+
+		It("should use the network of the MachineSets", func() {
+			createMachineSet("existing-network", "test-project")
+
+			_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: infraClusterKey})
+			Expect(err).ToNot(HaveOccurred())
+
+			gcpCluster := &gcpv1.GCPCluster{}
+			Expect(cl.Get(ctx, infraClusterKey, gcpCluster)).To(Succeed())
+			Expect(gcpCluster.Spec.Network.Name).To(HaveValue(Equal("existing-network")))
+		})
+
+		It("should keep the network of an existing GCPCluster", func() {
+			_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: infraClusterKey})
+			Expect(err).ToNot(HaveOccurred())
+
+			createMachineSet("existing-network", "test-project")
+
+			_, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: infraClusterKey})
+			Expect(err).ToNot(HaveOccurred())
+
+			gcpCluster := &gcpv1.GCPCluster{}
+			Expect(cl.Get(ctx, infraClusterKey, gcpCluster)).To(Succeed())
+			Expect(gcpCluster.Spec.Network.Name).To(HaveValue(Equal("test-infra-name-network")))
+		})
+
+		It("should skip the GCPCluster for a shared VPC host project network without degrading", func() {
+			createMachineSet("host-network", "host-project")
+
+			_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: infraClusterKey})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(cl.Get(ctx, infraClusterKey, &gcpv1.GCPCluster{})).NotTo(Succeed())
+			Expect(rec.Events).To(Receive(And(ContainSubstring(infraClusterNotSupportedReason), ContainSubstring("shared VPC host project host-project"))))
+
+			co := &configv1.ClusterOperator{}
+			Expect(cl.Get(ctx, client.ObjectKey{Name: controllers.ClusterOperatorName}, co)).To(Succeed())
+			Expect(v1helpers.IsStatusConditionTrue(co.Status.Conditions, infraClusterControllerName+"Degraded")).To(BeFalse())
+		})
+	})
+})
+
+var _ = Describe("Create IBMPowerVS infrastructure cluster", func() {
+	var infra *configv1.Infrastructure
+	var r *GenericInfraClusterReconciler
+
+	infraClusterKey := client.ObjectKey{Name: "test-infra-name", Namespace: controllers.DefaultManagedNamespace}
+
+	BeforeEach(func() {
+		machineAPINamespace := &corev1.Namespace{}
+		machineAPINamespace.SetName(controllers.MachineAPINamespace)
+		Expect(client.IgnoreAlreadyExists(cl.Create(ctx, machineAPINamespace))).To(Succeed())
+
+		infra = &configv1.Infrastructure{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: controllers.InfrastructureResourceName,
+			},
+		}
+		Expect(cl.Create(ctx, infra)).To(Succeed())
+
+		infra.Status = configv1.InfrastructureStatus{
+			InfrastructureName:   infraClusterKey.Name,
+			APIServerInternalURL: "https://api-int.test.example.com:6443",
+			PlatformStatus: &configv1.PlatformStatus{
+				Type: configv1.PowerVSPlatformType,
+				PowerVS: &configv1.PowerVSPlatformStatus{
+					Region: "dal",
+					Zone:   "dal12",
+				},
+			},
+		}
+		Expect(cl.Status().Update(ctx, infra)).To(Succeed())
+
+		r = &GenericInfraClusterReconciler{
+			ClusterOperatorStatusClient: operatorstatus.ClusterOperatorStatusClient{
+				Client:           cl,
+				Recorder:         record.NewFakeRecorder(32),
+				ManagedNamespace: controllers.DefaultManagedNamespace,
+				PlatformType:     configv1.PowerVSPlatformType,
+			},
+			InfraCluster:       &ibmcloudv1.IBMPowerVSCluster{},
+			NewInfraCluster:    NewIBMPowerVSCluster,
+			InitInfraCluster:   InitIBMPowerVSCluster,
+			SupportedPlatforms: map[string]bool{"powervs": true},
+		}
+	})
+
+	AfterEach(func() {
+		powerVSCluster := &ibmcloudv1.IBMPowerVSCluster{}
+		powerVSCluster.SetName(infraClusterKey.Name)
+		powerVSCluster.SetNamespace(infraClusterKey.Namespace)
+		co := &configv1.ClusterOperator{}
+		co.SetName(controllers.ClusterOperatorName)
+		Expect(test.CleanupAndWait(ctx, cl, powerVSCluster, infra, co)).To(Succeed())
+	})
+
+	Context("with Machine API Machines", func() {
+		var machine *unstructured.Unstructured
+
+		BeforeEach(func() {
+			machine = &unstructured.Unstructured{}
+			machine.SetAPIVersion("machine.openshift.io/v1beta1")
+			machine.SetKind("Machine")
+			machine.SetName("test-infra-name-master-0")
+			machine.SetNamespace(controllers.MachineAPINamespace)
+			Expect(unstructured.SetNestedField(machine.Object, map[string]interface{}{
+				"serviceInstance": map[string]interface{}{"type": "Name", "name": "test-service-instance"},
+				"network":         map[string]interface{}{"type": "RegEx", "regex": "^test-network$"},
+			}, "spec", "providerSpec", "value")).To(Succeed())
+			Expect(cl.Create(ctx, machine)).To(Succeed())
+
+			Expect(unstructured.SetNestedField(machine.Object, "test-service-instance-id", "status", "providerStatus", "serviceInstanceID")).To(Succeed())
+			Expect(cl.Status().Update(ctx, machine)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(test.CleanupAndWait(ctx, cl, machine)).To(Succeed())
+		})
+
+		It("should create an externally managed IBMPowerVSCluster from the infrastructure and the machines", func() {
+			_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: infraClusterKey})
+			Expect(err).ToNot(HaveOccurred())
+
+			powerVSCluster := &ibmcloudv1.IBMPowerVSCluster{}
+			Expect(cl.Get(ctx, infraClusterKey, powerVSCluster)).To(Succeed())
+			Expect(powerVSCluster.Annotations).To(HaveKey(clusterv1.ManagedByAnnotation))
+			Expect(powerVSCluster.Spec.ServiceInstanceID).To(Equal("test-service-instance-id"))
+			Expect(powerVSCluster.Spec.Network.RegEx).To(HaveValue(Equal("^test-network$")))
+			Expect(powerVSCluster.Spec.Network.ID).To(BeNil())
+			Expect(powerVSCluster.Spec.ControlPlaneEndpoint).To(Equal(clusterv1.APIEndpoint{Host: "api-int.test.example.com", Port: 6443}))
+			Expect(powerVSCluster.Status.Ready).To(BeTrue())
+		})
+
+		It("should keep the service instance and the network when the machines change", func() {
+			_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: infraClusterKey})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(unstructured.SetNestedField(machine.Object, map[string]interface{}{
+				"serviceInstance": map[string]interface{}{"type": "ID", "id": "other-service-instance-id"},
+				"network":         map[string]interface{}{"type": "ID", "id": "other-network-id"},
+			}, "spec", "providerSpec", "value")).To(Succeed())
+			Expect(cl.Update(ctx, machine)).To(Succeed())
+
+			_, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: infraClusterKey})
+			Expect(err).ToNot(HaveOccurred())
+
+			powerVSCluster := &ibmcloudv1.IBMPowerVSCluster{}
+			Expect(cl.Get(ctx, infraClusterKey, powerVSCluster)).To(Succeed())
+			Expect(powerVSCluster.Spec.ServiceInstanceID).To(Equal("test-service-instance-id"))
+			Expect(powerVSCluster.Spec.Network.RegEx).To(HaveValue(Equal("^test-network$")))
+			Expect(powerVSCluster.Spec.Network.ID).To(BeNil())
+		})
+
+		It("should keep reconciling the IBMPowerVSCluster when the machines are deleted", func() {
+			_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: infraClusterKey})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(test.CleanupAndWait(ctx, cl, machine)).To(Succeed())
+
+			_, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: infraClusterKey})
+			Expect(err).ToNot(HaveOccurred())
+
+			powerVSCluster := &ibmcloudv1.IBMPowerVSCluster{}
+			Expect(cl.Get(ctx, infraClusterKey, powerVSCluster)).To(Succeed())
+			Expect(powerVSCluster.Spec.ServiceInstanceID).To(Equal("test-service-instance-id"))
+		})
+	})
+
+	It("should fail when no machine has a service instance ID", func() {
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: infraClusterKey})
+		Expect(err).To(HaveOccurred())
+
+		Expect(cl.Get(ctx, infraClusterKey, &ibmcloudv1.IBMPowerVSCluster{})).NotTo(Succeed())
+	})
+})
+
+var _ = Describe("Power VS resource reference", func() {
+	It("should convert the set identifiers", func() {
+		ref := powerVSResourceReference(map[string]string{"type": "Name", "name": "test-network"})
+		Expect(ref.Name).To(HaveValue(Equal("test-network")))
+		Expect(ref.ID).To(BeNil())
+		Expect(ref.RegEx).To(BeNil())
+	})
+})
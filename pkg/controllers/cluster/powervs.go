@@ -0,0 +1,110 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ibmcloudv1 "sigs.k8s.io/cluster-api-provider-ibmcloud/api/v1beta1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/cluster-capi-operator/pkg/controllers"
+)
+
+var machineListGVK = schema.GroupVersionKind{Group: "machine.openshift.io", Version: "v1beta1", Kind: "MachineList"}
+
+// NewIBMPowerVSCluster returns an externally managed IBMPowerVSCluster for a PowerVS Infrastructure.
+func NewIBMPowerVSCluster(_ context.Context, _ client.Reader, infra *configv1.Infrastructure, _ string) (client.Object, []client.Object, error) {
+	if infra.Status.PlatformStatus == nil || infra.Status.PlatformStatus.PowerVS == nil {
+		return nil, nil, fmt.Errorf("infrastructure has no PowerVS platform status")
+	}
+
+	endpoint, err := controlPlaneEndpoint(infra)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &ibmcloudv1.IBMPowerVSCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        infra.Status.InfrastructureName,
+			Annotations: map[string]string{clusterv1.ManagedByAnnotation: ""},
+		},
+		Spec: ibmcloudv1.IBMPowerVSClusterSpec{
+			ControlPlaneEndpoint: endpoint,
+		},
+	}, nil, nil
+}
+
+// InitIBMPowerVSCluster sets the service instance ID and the network of a new IBMPowerVSCluster. They are only
+// derived at creation, so the existing values are kept when the Machines are replaced or refer to other resources.
+func InitIBMPowerVSCluster(ctx context.Context, cl client.Reader, _ *configv1.Infrastructure, infraCluster client.Object) error {
+	powerVSCluster, ok := infraCluster.(*ibmcloudv1.IBMPowerVSCluster)
+	if !ok {
+		return fmt.Errorf("expected an IBMPowerVSCluster, got %T", infraCluster)
+	}
+
+	serviceInstanceID, network, err := powerVSMachineResources(ctx, cl)
+	if err != nil {
+		return err
+	}
+	powerVSCluster.Spec.ServiceInstanceID = serviceInstanceID
+	powerVSCluster.Spec.Network = network
+
+	return nil
+}
+
+// powerVSMachineResources returns the service instance ID and the network of the Machine API Machines, as the
+// Infrastructure does not record them. A Machine referring to its service instance by name has the ID in its provider status.
+func powerVSMachineResources(ctx context.Context, cl client.Reader) (string, ibmcloudv1.IBMPowerVSResourceReference, error) {
+	machines := &unstructured.UnstructuredList{}
+	machines.SetGroupVersionKind(machineListGVK)
+	if err := cl.List(ctx, machines, client.InNamespace(controllers.MachineAPINamespace)); err != nil && !meta.IsNoMatchError(err) {
+		return "", ibmcloudv1.IBMPowerVSResourceReference{}, fmt.Errorf("unable to list Machine API Machines: %v", err)
+	}
+
+	for _, machine := range machines.Items {
+		serviceInstanceID, _, err := unstructured.NestedString(machine.Object, "spec", "providerSpec", "value", "serviceInstance", "id")
+		if err != nil {
+			return "", ibmcloudv1.IBMPowerVSResourceReference{}, fmt.Errorf("unable to read service instance of Machine %s: %v", machine.GetName(), err)
+		}
+		if serviceInstanceID == "" {
+			serviceInstanceID, _, err = unstructured.NestedString(machine.Object, "status", "providerStatus", "serviceInstanceID")
+			if err != nil {
+				return "", ibmcloudv1.IBMPowerVSResourceReference{}, fmt.Errorf("unable to read service instance ID of Machine %s: %v", machine.GetName(), err)
+			}
+		}
+
+		network, _, err := unstructured.NestedStringMap(machine.Object, "spec", "providerSpec", "value", "network")
+		if err != nil {
+			return "", ibmcloudv1.IBMPowerVSResourceReference{}, fmt.Errorf("unable to read network of Machine %s: %v", machine.GetName(), err)
+		}
+		networkRef := powerVSResourceReference(network)
+
+		if serviceInstanceID != "" && networkRef != (ibmcloudv1.IBMPowerVSResourceReference{}) {
+			return serviceInstanceID, networkRef, nil
+		}
+	}
+
+	return "", ibmcloudv1.IBMPowerVSResourceReference{}, fmt.Errorf("no Machine API Machine in %s has a Power VS service instance ID and network", controllers.MachineAPINamespace)
+}
+
+// powerVSResourceReference converts a Machine API Power VS resource, identified by its ID, name or regular expression,
+// to the provider resource reference.
+func powerVSResourceReference(resource map[string]string) ibmcloudv1.IBMPowerVSResourceReference {
+	ref := ibmcloudv1.IBMPowerVSResourceReference{}
+	if id := resource["id"]; id != "" {
+		ref.ID = &id
+	}
+	if name := resource["name"]; name != "" {
+		ref.Name = &name
+	}
+	if regex := resource["regex"]; regex != "" {
+		ref.RegEx = &regex
+	}
+	return ref
+}
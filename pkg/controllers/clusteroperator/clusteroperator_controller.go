@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -19,6 +20,7 @@ import (
 	"github.com/openshift/cluster-capi-operator/assets"
 	"github.com/openshift/cluster-capi-operator/pkg/controllers"
 	"github.com/openshift/cluster-capi-operator/pkg/operatorstatus"
+	"github.com/openshift/cluster-capi-operator/pkg/util"
 )
 
 // ClusterOperatorReconciler reconciles a ClusterOperator object
@@ -26,6 +28,7 @@ type ClusterOperatorReconciler struct {
 	operatorstatus.ClusterOperatorStatusClient
 	Scheme             *runtime.Scheme
 	Images             map[string]string
+	ImageOverrides     map[string]string
 	PlatformType       string
 	SupportedPlatforms map[string]bool
 }
@@ -39,6 +42,36 @@ func (r *ClusterOperatorReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			handler.EnqueueRequestsFromMapFunc(toClusterOperator),
 			builder.WithPredicates(infrastructurePredicates()),
 		).
+		Watches(
+			&source.Kind{Type: &configv1.Proxy{}},
+			handler.EnqueueRequestsFromMapFunc(toClusterOperator),
+			builder.WithPredicates(proxyPredicates()),
+		).
+		Watches(
+			&source.Kind{Type: &operatorv1.CoreProvider{}},
+			handler.EnqueueRequestsFromMapFunc(toClusterOperator),
+			builder.WithPredicates(providerPredicates()),
+		).
+		Watches(
+			&source.Kind{Type: &operatorv1.InfrastructureProvider{}},
+			handler.EnqueueRequestsFromMapFunc(toClusterOperator),
+			builder.WithPredicates(providerPredicates()),
+		).
+		Watches(
+			&source.Kind{Type: &corev1.ConfigMap{}},
+			handler.EnqueueRequestsFromMapFunc(toClusterOperator),
+			builder.WithPredicates(configMapPredicates(r.ManagedNamespace)),
+		).
+		Watches(
+			&source.Kind{Type: &appsv1.Deployment{}},
+			handler.EnqueueRequestsFromMapFunc(toClusterOperator),
+			builder.WithPredicates(deploymentPredicates(r.ManagedNamespace)),
+		).
+		Watches(
+			&source.Kind{Type: &corev1.Pod{}},
+			handler.EnqueueRequestsFromMapFunc(toClusterOperator),
+			builder.WithPredicates(podPredicates(r.ManagedNamespace)),
+		).
 		Complete(r)
 }
 
@@ -63,8 +96,19 @@ func (r *ClusterOperatorReconciler) Reconcile(ctx context.Context, _ ctrl.Reques
 		return ctrl.Result{}, err
 	}
 
+	// Pass the cluster-wide proxy to the provider containers
+	proxyEnv, err := r.proxyEnvironment(ctx)
+	if err != nil {
+		log.Error(err, "unable to get cluster-wide proxy")
+		if err := r.SetStatusDegraded(ctx, err); err != nil {
+			return ctrl.Result{}, fmt.Errorf("error syncing ClusterOperatorStatus: %v", err)
+		}
+		return ctrl.Result{}, err
+	}
+
 	// Install core CAPI components
-	if err := r.installCoreCAPIComponents(ctx); err != nil {
+	components, err := r.installCoreCAPIComponents(ctx, proxyEnv)
+	if err != nil {
 		log.Error(err, "unable to install core CAPI components")
 		if err := r.SetStatusDegraded(ctx, err); err != nil {
 			return ctrl.Result{}, fmt.Errorf("error syncing ClusterOperatorStatus: %v", err)
@@ -72,77 +116,115 @@ func (r *ClusterOperatorReconciler) Reconcile(ctx context.Context, _ ctrl.Reques
 		return ctrl.Result{}, err
 	}
 
-	// Set platform type
-	if infra.Status.PlatformStatus == nil {
-		log.Info("no platform status exists in infrastructure object. Skipping...")
-		if err := r.SetStatusAvailable(ctx); err != nil {
-			return ctrl.Result{}, err
+	supported, err := r.isPlatformSupported(ctx, infra)
+	if err != nil {
+		log.Error(err, "unable to determine whether the infrastructure provider is enabled")
+		if err := r.SetStatusDegraded(ctx, err); err != nil {
+			return ctrl.Result{}, fmt.Errorf("error syncing ClusterOperatorStatus: %v", err)
 		}
-		return ctrl.Result{}, nil
+		return ctrl.Result{}, err
 	}
-	r.PlatformType = strings.ToLower(string(infra.Status.PlatformStatus.Type))
 
-	// Check if platform type is supported
-	if _, ok := r.SupportedPlatforms[r.PlatformType]; !ok {
-		log.Info("platform type is not supported. Skipping...", "platformType", r.PlatformType)
-		if err := r.SetStatusAvailable(ctx); err != nil {
+	if supported {
+		// Install infrastructure CAPI components
+		infraComponents, err := r.installInfrastructureCAPIComponents(ctx, proxyEnv)
+		if err != nil {
+			log.Error(err, "unable to infrastructure core CAPI components")
+			if err := r.SetStatusDegraded(ctx, err); err != nil {
+				return ctrl.Result{}, fmt.Errorf("error syncing ClusterOperatorStatus: %v", err)
+			}
 			return ctrl.Result{}, err
 		}
-		return ctrl.Result{}, nil
+		components = append(components, infraComponents...)
+	}
+
+	// Remove previously applied components that are no longer part of the assets
+	if err := r.pruneComponents(ctx, components); err != nil {
+		log.Error(err, "unable to prune stale CAPI components")
+		if err := r.SetStatusDegraded(ctx, err); err != nil {
+			return ctrl.Result{}, fmt.Errorf("error syncing ClusterOperatorStatus: %v", err)
+		}
+		return ctrl.Result{}, err
 	}
 
-	// Install infrastructure CAPI components
-	if err := r.installInfrastructureCAPIComponents(ctx); err != nil {
-		log.Error(err, "unable to infrastructure core CAPI components")
+	// Report the state of the provider deployments, naming every component that is failing
+	statuses, err := r.componentsStatus(ctx, components)
+	if err != nil {
+		log.Error(err, "unable to get CAPI components status")
 		if err := r.SetStatusDegraded(ctx, err); err != nil {
 			return ctrl.Result{}, fmt.Errorf("error syncing ClusterOperatorStatus: %v", err)
 		}
 		return ctrl.Result{}, err
 	}
 
-	return ctrl.Result{}, r.SetStatusAvailable(ctx)
+	return ctrl.Result{}, r.SetStatusFromComponents(ctx, statuses)
+}
+
+// isPlatformSupported sets the platform type from the infrastructure object and
+// reports whether infrastructure CAPI components should be installed for it.
+func (r *ClusterOperatorReconciler) isPlatformSupported(ctx context.Context, infra *configv1.Infrastructure) (bool, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	// Set platform type
+	if infra.Status.PlatformStatus == nil {
+		log.Info("no platform status exists in infrastructure object. Skipping...")
+		return false, nil
+	}
+	r.PlatformType = strings.ToLower(string(infra.Status.PlatformStatus.Type))
+
+	// Check if the provider of the platform is enabled
+	enabled, err := util.IsProviderEnabled(ctx, r.Client, r.ManagedNamespace, r.SupportedPlatforms, infra.Status.PlatformStatus.Type)
+	if err != nil {
+		return false, err
+	}
+	if !enabled {
+		log.Info("platform type is not supported or its provider is disabled. Skipping...", "platformType", r.PlatformType)
+		return false, nil
+	}
+
+	return true, nil
 }
 
 // installCoreCAPIComponents reads assets from assets/core-capi, create CRs that are consumed by upstream CAPI Operator
-func (r *ClusterOperatorReconciler) installCoreCAPIComponents(ctx context.Context) error {
+func (r *ClusterOperatorReconciler) installCoreCAPIComponents(ctx context.Context, proxyEnv []corev1.EnvVar) ([]client.Object, error) {
 	log := ctrl.LoggerFrom(ctx)
 	log.Info("reconciling Core CAPI components")
 	objs, err := assets.ReadCoreProviderAssets(r.Scheme)
 	if err != nil {
-		return fmt.Errorf("unable to read core-capi: %v", err)
+		return nil, fmt.Errorf("unable to read core-capi: %v", err)
 	}
 
 	coreProvider := objs[assets.CoreProviderKey].(*operatorv1.CoreProvider)
-	if err := r.reconcileCoreProvider(ctx, coreProvider); err != nil {
-		return fmt.Errorf("unable to reconcile CoreProvider: %v", err)
+	if err := r.reconcileCoreProvider(ctx, coreProvider, proxyEnv); err != nil {
+		return nil, fmt.Errorf("unable to reconcile CoreProvider: %v", err)
 	}
 
 	coreProviderCM := objs[assets.CoreProviderConfigMapKey].(*corev1.ConfigMap)
 	if err := r.reconcileConfigMap(ctx, coreProviderCM); err != nil {
-		return fmt.Errorf("unable to reconcile core provider ConfigMap: %v", err)
+		return nil, fmt.Errorf("unable to reconcile core provider ConfigMap: %v", err)
 	}
 
-	return nil
+	return []client.Object{coreProvider, coreProviderCM}, nil
 }
 
 // installInfrastructureCAPIComponents reads assets from assets/providers, create CRs that are consumed by upstream CAPI Operator
-func (r *ClusterOperatorReconciler) installInfrastructureCAPIComponents(ctx context.Context) error {
+func (r *ClusterOperatorReconciler) installInfrastructureCAPIComponents(ctx context.Context, proxyEnv []corev1.EnvVar) ([]client.Object, error) {
 	log := ctrl.LoggerFrom(ctx)
 	log.Info("reconciling Infrastructure CAPI components")
 	objs, err := assets.ReadInfrastructureProviderAssets(r.Scheme, r.PlatformType)
 	if err != nil {
-		return fmt.Errorf("unable to read providers: %v", err)
+		return nil, fmt.Errorf("unable to read providers: %v", err)
 	}
 
 	infraProvider := objs[assets.InfrastructureProviderKey].(*operatorv1.InfrastructureProvider)
-	if err := r.reconcileInfrastructureProvider(ctx, infraProvider); err != nil {
-		return fmt.Errorf("unable to reconcile InfrastructureProvider: %v", err)
+	if err := r.reconcileInfrastructureProvider(ctx, infraProvider, proxyEnv); err != nil {
+		return nil, fmt.Errorf("unable to reconcile InfrastructureProvider: %v", err)
 	}
 
 	infraProviderCM := objs[assets.InfrastructureProviderConfigMapKey].(*corev1.ConfigMap)
 	if err := r.reconcileConfigMap(ctx, infraProviderCM); err != nil {
-		return fmt.Errorf("unable to reconcile infrastructure provider ConfigMap: %v", err)
+		return nil, fmt.Errorf("unable to reconcile infrastructure provider ConfigMap: %v", err)
 	}
 
-	return nil
+	return []client.Object{infraProvider, infraProviderCM}, nil
 }
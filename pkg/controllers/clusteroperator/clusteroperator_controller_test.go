@@ -0,0 +1,296 @@
+package clusteroperator
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	operatorv1 "sigs.k8s.io/cluster-api-operator/api/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/cluster-capi-operator/pkg/controllers"
+	"github.com/openshift/cluster-capi-operator/pkg/operatorstatus"
+	"github.com/openshift/cluster-capi-operator/pkg/test"
+	"github.com/openshift/cluster-capi-operator/pkg/util"
+)
+
+const timeout = 10 * time.Second
+
+var _ = Describe("ClusterOperator controller", func() {
+	var mgrCtxCancel context.CancelFunc
+	var mgrStopped chan struct{}
+	var infra *configv1.Infrastructure
+
+	ctx := context.Background()
+	coreProviderKey := client.ObjectKey{Name: "cluster-api", Namespace: controllers.DefaultManagedNamespace}
+	ibmCloudProviderKey := client.ObjectKey{Name: "ibmcloud", Namespace: controllers.DefaultManagedNamespace}
+
+	BeforeEach(func() {
+		By("Creating the infrastructure object")
+		infra = &configv1.Infrastructure{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: controllers.InfrastructureResourceName,
+			},
+		}
+		Expect(cl.Create(ctx, infra)).To(Succeed())
+
+		By("Setting up a new manager")
+		mgr, err := manager.New(cfg, manager.Options{MetricsBindAddress: "0"})
+		Expect(err).NotTo(HaveOccurred())
+
+		reconciler := &ClusterOperatorReconciler{
+			ClusterOperatorStatusClient: operatorstatus.ClusterOperatorStatusClient{
+				Client:           mgr.GetClient(),
+				Recorder:         mgr.GetEventRecorderFor("cluster-capi-operator-cluster-operator-controller"),
+				ManagedNamespace: controllers.DefaultManagedNamespace,
+			},
+			Scheme:             scheme.Scheme,
+			SupportedPlatforms: map[string]bool{"ibmcloud": true},
+		}
+		Expect(reconciler.SetupWithManager(mgr)).To(Succeed())
+
+		var mgrCtx context.Context
+		mgrCtx, mgrCtxCancel = context.WithCancel(ctx)
+		mgrStopped = make(chan struct{})
+
+		By("Starting the manager")
+		go func() {
+			defer GinkgoRecover()
+			defer close(mgrStopped)
+
+			Expect(mgr.Start(mgrCtx)).To(Succeed())
+		}()
+	})
+
+	AfterEach(func() {
+		By("Closing the manager")
+		mgrCtxCancel()
+		Eventually(mgrStopped, timeout).Should(BeClosed())
+
+		By("Cleanup resources")
+		co := &configv1.ClusterOperator{}
+		co.SetName(controllers.ClusterOperatorName)
+		coreProvider := &operatorv1.CoreProvider{}
+		coreProvider.SetName(coreProviderKey.Name)
+		coreProvider.SetNamespace(coreProviderKey.Namespace)
+		coreProviderCM := &corev1.ConfigMap{}
+		coreProviderCM.SetName(coreProviderKey.Name)
+		coreProviderCM.SetNamespace(coreProviderKey.Namespace)
+		ibmCloudProvider := &operatorv1.InfrastructureProvider{}
+		ibmCloudProvider.SetName(ibmCloudProviderKey.Name)
+		ibmCloudProvider.SetNamespace(ibmCloudProviderKey.Namespace)
+		ibmCloudProviderCM := &corev1.ConfigMap{}
+		ibmCloudProviderCM.SetName(ibmCloudProviderKey.Name)
+		ibmCloudProviderCM.SetNamespace(ibmCloudProviderKey.Namespace)
+		Expect(test.CleanupAndWait(ctx, cl, infra, co, coreProvider, coreProviderCM, ibmCloudProvider, ibmCloudProviderCM)).To(Succeed())
+	})
+
+	It("should recreate a deleted core provider without waiting for the sync period", func() {
+		coreProvider := &operatorv1.CoreProvider{}
+		Eventually(func() error {
+			return cl.Get(ctx, coreProviderKey, coreProvider)
+		}, timeout).Should(Succeed())
+		originalUID := coreProvider.GetUID()
+
+		Expect(cl.Delete(ctx, coreProvider)).To(Succeed())
+
+		Eventually(func() (types.UID, error) {
+			recreated := &operatorv1.CoreProvider{}
+			if err := cl.Get(ctx, coreProviderKey, recreated); err != nil {
+				return "", err
+			}
+			return recreated.GetUID(), nil
+		}, timeout).ShouldNot(Equal(originalUID))
+	})
+
+	It("should install the ibmcloud provider once the platform is IBMCloud", func() {
+		Eventually(func() error {
+			return cl.Get(ctx, coreProviderKey, &operatorv1.CoreProvider{})
+		}, timeout).Should(Succeed())
+		Expect(cl.Get(ctx, ibmCloudProviderKey, &operatorv1.InfrastructureProvider{})).NotTo(Succeed())
+
+		infra.Status.PlatformStatus = &configv1.PlatformStatus{
+			Type: configv1.IBMCloudPlatformType,
+			IBMCloud: &configv1.IBMCloudPlatformStatus{
+				ProviderType: configv1.IBMCloudProviderTypeVPC,
+			},
+		}
+		Expect(cl.Status().Update(ctx, infra)).To(Succeed())
+
+		Eventually(func() error {
+			return cl.Get(ctx, ibmCloudProviderKey, &operatorv1.InfrastructureProvider{})
+		}, timeout).Should(Succeed())
+		Expect(cl.Get(ctx, ibmCloudProviderKey, &corev1.ConfigMap{})).To(Succeed())
+	})
+})
+
+var _ = Describe("Provider overrides", func() {
+	var r *ClusterOperatorReconciler
+	var providersConfigMap *corev1.ConfigMap
+
+	ctx := context.Background()
+	infra := &configv1.Infrastructure{
+		Status: configv1.InfrastructureStatus{
+			PlatformStatus: &configv1.PlatformStatus{Type: configv1.AWSPlatformType},
+		},
+	}
+
+	BeforeEach(func() {
+		r = &ClusterOperatorReconciler{
+			ClusterOperatorStatusClient: operatorstatus.ClusterOperatorStatusClient{
+				Client:           cl,
+				ManagedNamespace: controllers.DefaultManagedNamespace,
+			},
+			SupportedPlatforms: map[string]bool{"aws": true},
+		}
+
+		providersConfigMap = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      util.ProviderOverridesConfigMapName,
+				Namespace: controllers.DefaultManagedNamespace,
+			},
+			Data: map[string]string{},
+		}
+	})
+
+	AfterEach(func() {
+		Expect(test.CleanupAndWait(ctx, cl, providersConfigMap)).To(Succeed())
+	})
+
+	It("should follow the supported platforms without the providers ConfigMap", func() {
+		supported, err := r.isPlatformSupported(ctx, infra)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(supported).To(BeTrue())
+	})
+
+	It("should not install a disabled provider", func() {
+		providersConfigMap.Data[util.DisabledProvidersKey] = "gcp, AWS"
+		Expect(cl.Create(ctx, providersConfigMap)).To(Succeed())
+
+		supported, err := r.isPlatformSupported(ctx, infra)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(supported).To(BeFalse())
+	})
+
+	It("should install an enabled provider for an unsupported platform", func() {
+		r.SupportedPlatforms = map[string]bool{}
+		providersConfigMap.Data[util.EnabledProvidersKey] = "aws"
+		Expect(cl.Create(ctx, providersConfigMap)).To(Succeed())
+
+		supported, err := r.isPlatformSupported(ctx, infra)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(supported).To(BeTrue())
+	})
+
+	It("should reject a provider that is both enabled and disabled", func() {
+		providersConfigMap.Data[util.EnabledProvidersKey] = "aws"
+		providersConfigMap.Data[util.DisabledProvidersKey] = "aws"
+		Expect(cl.Create(ctx, providersConfigMap)).To(Succeed())
+
+		_, err := r.isPlatformSupported(ctx, infra)
+		Expect(err).To(MatchError(ContainSubstring("platform aws is both enabled and disabled")))
+	})
+})
+
+var _ = Describe("Watch predicates", func() {
+	ctx := context.Background()
+
+	var deployment *appsv1.Deployment
+
+	BeforeEach(func() {
+		deployment = &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "capi-controller-manager",
+				Namespace: controllers.DefaultManagedNamespace,
+				Labels:    map[string]string{providerLabel: "cluster-api"},
+			},
+			Spec: appsv1.DeploymentSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"control-plane": "controller-manager"}},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"control-plane": "controller-manager"}},
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: "manager", Image: "capi:latest"}},
+					},
+				},
+			},
+		}
+		Expect(cl.Create(ctx, deployment)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(test.CleanupAndWait(ctx, cl, deployment)).To(Succeed())
+	})
+
+	It("should only pass provider pods in the managed namespace", func() {
+		providerPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:      "capi-controller-manager-1",
+			Namespace: controllers.DefaultManagedNamespace,
+			Labels:    map[string]string{providerLabel: "cluster-api"},
+		}}
+		otherNamespacePod := providerPod.DeepCopy()
+		otherNamespacePod.Namespace = "other"
+		otherPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:      "other",
+			Namespace: controllers.DefaultManagedNamespace,
+			Labels:    map[string]string{"app": "other"},
+		}}
+
+		p := podPredicates(controllers.DefaultManagedNamespace)
+		Expect(p.Create(event.CreateEvent{Object: providerPod})).To(BeTrue())
+		Expect(p.Create(event.CreateEvent{Object: otherNamespacePod})).To(BeFalse())
+		Expect(p.Create(event.CreateEvent{Object: otherPod})).To(BeFalse())
+	})
+
+	It("should only pass pod updates changing the container states", func() {
+		oldPod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "capi-controller-manager-1",
+				Namespace: controllers.DefaultManagedNamespace,
+				Labels:    map[string]string{providerLabel: "cluster-api"},
+			},
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{{Name: "manager", Ready: true}},
+			},
+		}
+
+		resynced := oldPod.DeepCopy()
+		resynced.ResourceVersion = "2"
+		crashing := oldPod.DeepCopy()
+		crashing.Status.ContainerStatuses[0] = corev1.ContainerStatus{
+			Name:  "manager",
+			State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+		}
+
+		p := podPredicates(controllers.DefaultManagedNamespace)
+		Expect(p.Update(event.UpdateEvent{ObjectOld: oldPod, ObjectNew: resynced})).To(BeFalse())
+		Expect(p.Update(event.UpdateEvent{ObjectOld: oldPod, ObjectNew: crashing})).To(BeTrue())
+	})
+
+	It("should only pass deployment updates changing the conditions", func() {
+		oldDeployment := deployment.DeepCopy()
+		oldDeployment.Status.Conditions = []appsv1.DeploymentCondition{{
+			Type:           appsv1.DeploymentAvailable,
+			Status:         corev1.ConditionTrue,
+			LastUpdateTime: metav1.NewTime(time.Now().Add(-time.Minute)),
+		}}
+
+		refreshed := oldDeployment.DeepCopy()
+		refreshed.Status.ObservedGeneration++
+		refreshed.Status.Conditions[0].LastUpdateTime = metav1.Now()
+		unavailable := oldDeployment.DeepCopy()
+		unavailable.Status.Conditions[0].Status = corev1.ConditionFalse
+
+		p := deploymentPredicates(controllers.DefaultManagedNamespace)
+		Expect(p.Update(event.UpdateEvent{ObjectOld: oldDeployment, ObjectNew: refreshed})).To(BeFalse())
+		Expect(p.Update(event.UpdateEvent{ObjectOld: oldDeployment, ObjectNew: unavailable})).To(BeTrue())
+	})
+})
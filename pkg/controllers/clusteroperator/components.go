@@ -1,77 +1,494 @@
 package clusteroperator
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"sort"
 	"strings"
 
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
 	operatorv1 "sigs.k8s.io/cluster-api-operator/api/v1alpha1"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/structured-merge-diff/v4/fieldpath"
+	"sigs.k8s.io/structured-merge-diff/v4/value"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/cluster-capi-operator/assets"
+	"github.com/openshift/cluster-capi-operator/pkg/controllers"
+	"github.com/openshift/cluster-capi-operator/pkg/operatorstatus"
+	"github.com/openshift/cluster-capi-operator/pkg/util"
 )
 
-func (r *ClusterOperatorReconciler) reconcileCoreProvider(ctx context.Context, coreProvider *operatorv1.CoreProvider) error {
-	coreProviderCopy := coreProvider.DeepCopy()
-	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, coreProvider, func() error {
-		coreProvider.TypeMeta = coreProviderCopy.TypeMeta
-		coreProvider.Spec = coreProviderCopy.Spec
-		containers := coreProvider.Spec.Deployment.Containers
-		coreProvider.Spec.ProviderSpec.Deployment = &operatorv1.DeploymentSpec{
-			Containers: r.containerCustomizationFromProvider(coreProvider.Kind, coreProvider.Name, containers),
-		}
-		return nil
-	}); err != nil {
-		return fmt.Errorf("unable to create or update CoreProvider: %v", err)
+const (
+	// componentsConfigMapKey is the key of the provider ConfigMaps holding the provider components YAML.
+	componentsConfigMapKey = "components"
+
+	// providerLabel is set by the upstream CAPI operator on every provider component.
+	providerLabel = util.ProviderLabel
+
+	// fieldManager is the server-side apply field manager used for all objects applied by the operator.
+	fieldManager = "cluster-capi-operator"
+
+	applyConflictReason = "ApplyConflict"
+
+	// managedByLabel is set on every object applied by the operator, so objects that were
+	// dropped from the assets can be found and pruned.
+	managedByLabel = "cluster-capi-operator.openshift.io/managed-by"
+
+	// preventPruningAnnotation pins an applied object, keeping it even after it was dropped from the assets.
+	preventPruningAnnotation = "cluster-capi-operator.openshift.io/prevent-pruning"
+)
+
+func (r *ClusterOperatorReconciler) reconcileCoreProvider(ctx context.Context, coreProvider *operatorv1.CoreProvider, proxyEnv []corev1.EnvVar) error {
+	containers := coreProvider.Spec.Deployment.Containers
+	coreProvider.Spec.ProviderSpec.Deployment = &operatorv1.DeploymentSpec{
+		Containers: r.containerCustomizationFromProvider(coreProvider.Kind, coreProvider.Name, containers, proxyEnv),
+	}
+
+	if err := r.applyObject(ctx, coreProvider); err != nil {
+		return fmt.Errorf("unable to apply CoreProvider: %v", err)
 	}
 
 	return nil
 }
 
-func (r *ClusterOperatorReconciler) reconcileInfrastructureProvider(ctx context.Context, infraProvider *operatorv1.InfrastructureProvider) error {
-	infraProviderCopy := infraProvider.DeepCopy()
-	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, infraProvider, func() error {
-		infraProvider.TypeMeta = infraProviderCopy.TypeMeta
-		infraProvider.Spec = infraProviderCopy.Spec
-		containers := infraProviderCopy.Spec.Deployment.Containers
-		infraProvider.Spec.ProviderSpec.Deployment = &operatorv1.DeploymentSpec{
-			Containers: r.containerCustomizationFromProvider(infraProvider.Kind, infraProvider.Name, containers),
-		}
-		return nil
-	}); err != nil {
-		return fmt.Errorf("unable to create or update InfrastructureProvider: %v", err)
+func (r *ClusterOperatorReconciler) reconcileInfrastructureProvider(ctx context.Context, infraProvider *operatorv1.InfrastructureProvider, proxyEnv []corev1.EnvVar) error {
+	containers := infraProvider.Spec.Deployment.Containers
+	infraProvider.Spec.ProviderSpec.Deployment = &operatorv1.DeploymentSpec{
+		Containers: r.containerCustomizationFromProvider(infraProvider.Kind, infraProvider.Name, containers, proxyEnv),
 	}
+
+	if err := r.applyObject(ctx, infraProvider); err != nil {
+		return fmt.Errorf("unable to apply InfrastructureProvider: %v", err)
+	}
+
 	return nil
 }
 
 func (r *ClusterOperatorReconciler) reconcileConfigMap(ctx context.Context, configMap *corev1.ConfigMap) error {
-	cmCopy := configMap.DeepCopy()
-	if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, configMap, func() error {
-		configMap.Labels = cmCopy.Labels
-		configMap.Data = cmCopy.Data
-		return nil
-	}); err != nil {
-		return fmt.Errorf("unable to create or update core Cluster API Configmap: %v", err)
+	if err := r.applyObject(ctx, configMap); err != nil {
+		return fmt.Errorf("unable to apply core Cluster API Configmap: %v", err)
+	}
+
+	return nil
+}
+
+// applyObject server-side applies the object with the operator field manager, so only the fields
+// present in the desired object are asserted and fields set by other actors are left untouched.
+// Ownership is never forced: when another manager owns one of the fields, e.g. the replicas of a
+// scaled Deployment, the conflict is reported and the object is applied again without that field.
+func (r *ClusterOperatorReconciler) applyObject(ctx context.Context, obj client.Object) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	gvk, err := apiutil.GVKForObject(obj, r.Client.Scheme())
+	if err != nil {
+		return fmt.Errorf("unable to get GroupVersionKind for object: %w", err)
+	}
+
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[managedByLabel] = fieldManager
+	obj.SetLabels(labels)
+
+	// Server populated metadata must not be part of an apply request.
+	obj.SetManagedFields(nil)
+	obj.SetResourceVersion("")
+	obj.SetUID("")
+	obj.SetGeneration(0)
+	obj.SetCreationTimestamp(metav1.Time{})
+
+	err = r.Patch(ctx, obj, client.Apply, client.FieldOwner(fieldManager))
+	if !k8serrors.IsConflict(err) {
+		return err
+	}
+
+	desired, dropped, dropErr := r.withoutConflictingFields(ctx, obj, conflictingFields(err))
+	if dropErr != nil {
+		log.Error(dropErr, "unable to resolve field conflict", "kind", gvk.Kind, "name", obj.GetName())
+		return err
+	}
+
+	log.Info("fields are managed by another manager, leaving them untouched", "kind", gvk.Kind, "name", obj.GetName(), "fields", dropped)
+	r.Recorder.Eventf(obj, corev1.EventTypeWarning, applyConflictReason, "Leaving %s managed by another manager untouched", strings.Join(dropped, ", "))
+
+	if err := r.Patch(ctx, desired, client.Apply, client.FieldOwner(fieldManager)); err != nil {
+		return err
+	}
+
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(desired.Object, obj)
+}
+
+// conflictingFields returns the paths of the fields owned by another manager from a server-side apply conflict.
+func conflictingFields(err error) map[string]bool {
+	fields := map[string]bool{}
+
+	var statusErr *k8serrors.StatusError
+	if !errors.As(err, &statusErr) || statusErr.ErrStatus.Details == nil {
+		return fields
+	}
+
+	for _, cause := range statusErr.ErrStatus.Details.Causes {
+		if cause.Type == metav1.CauseTypeFieldManagerConflict {
+			fields[cause.Field] = true
+		}
+	}
+
+	return fields
+}
+
+// withoutConflictingFields returns the object to apply without the conflicting fields, together with the
+// sorted paths of the dropped fields. The paths are looked up in the managed fields of the live object,
+// so every conflict is resolved or an error is returned.
+func (r *ClusterOperatorReconciler) withoutConflictingFields(ctx context.Context, obj client.Object, conflicts map[string]bool) (*unstructured.Unstructured, []string, error) {
+	if len(conflicts) == 0 {
+		return nil, nil, fmt.Errorf("conflict does not name any field")
+	}
+
+	live := &unstructured.Unstructured{}
+	live.SetGroupVersionKind(obj.GetObjectKind().GroupVersionKind())
+	if err := r.Get(ctx, client.ObjectKeyFromObject(obj), live); err != nil {
+		return nil, nil, fmt.Errorf("unable to get %s: %v", obj.GetName(), err)
+	}
+
+	paths := map[string]fieldpath.Path{}
+	for _, entry := range live.GetManagedFields() {
+		if entry.Manager == fieldManager || entry.FieldsV1 == nil {
+			continue
+		}
+
+		set := &fieldpath.Set{}
+		if err := set.FromJSON(bytes.NewReader(entry.FieldsV1.Raw)); err != nil {
+			return nil, nil, fmt.Errorf("unable to read managed fields of %s: %v", entry.Manager, err)
+		}
+
+		set.Iterate(func(path fieldpath.Path) {
+			if conflicts[path.String()] {
+				paths[path.String()] = path.Copy()
+			}
+		})
+	}
+
+	if len(paths) != len(conflicts) {
+		return nil, nil, fmt.Errorf("conflicting fields are not managed by another manager")
+	}
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to convert to unstructured: %v", err)
+	}
+
+	dropped := []string{}
+	for name, path := range paths {
+		removeFieldPath(content, path)
+		dropped = append(dropped, name)
+	}
+	sort.Strings(dropped)
+
+	return &unstructured.Unstructured{Object: content}, dropped, nil
+}
+
+// removeFieldPath removes the field at the given path from the unstructured node and returns the node.
+// List elements are matched by key, value or index like in the managed fields.
+func removeFieldPath(node interface{}, path fieldpath.Path) interface{} {
+	if len(path) == 0 {
+		return node
+	}
+	element, rest := path[0], path[1:]
+
+	switch n := node.(type) {
+	case map[string]interface{}:
+		if element.FieldName == nil {
+			return node
+		}
+
+		child, ok := n[*element.FieldName]
+		if !ok {
+			return node
+		}
+
+		if len(rest) == 0 {
+			delete(n, *element.FieldName)
+		} else {
+			n[*element.FieldName] = removeFieldPath(child, rest)
+		}
+	case []interface{}:
+		for i, item := range n {
+			if !matchesPathElement(item, i, element) {
+				continue
+			}
+
+			if len(rest) == 0 {
+				return append(n[:i:i], n[i+1:]...)
+			}
+			n[i] = removeFieldPath(item, rest)
+			break
+		}
+	}
+
+	return node
+}
+
+// matchesPathElement returns whether the list item at the given index is selected by the path element.
+func matchesPathElement(item interface{}, index int, element fieldpath.PathElement) bool {
+	switch {
+	case element.Index != nil:
+		return *element.Index == index
+	case element.Value != nil:
+		return value.Equals(value.NewValueInterface(item), *element.Value)
+	case element.Key != nil:
+		fields, ok := item.(map[string]interface{})
+		if !ok {
+			return false
+		}
+
+		for _, key := range *element.Key {
+			field, ok := fields[key.Name]
+			if !ok || !value.Equals(value.NewValueInterface(field), key.Value) {
+				return false
+			}
+		}
+		return true
 	}
+
+	return false
+}
+
+// pruneComponents deletes objects previously applied by the operator that are not part of the desired components.
+// Objects carrying the prevent pruning annotation are kept. CRDs are managed by the CVO and are never pruned.
+func (r *ClusterOperatorReconciler) pruneComponents(ctx context.Context, desired []client.Object) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	desiredKeys := map[string]bool{}
+	for _, obj := range desired {
+		key, err := r.componentKey(obj)
+		if err != nil {
+			return err
+		}
+		desiredKeys[key] = true
+	}
+
+	lists := []client.ObjectList{
+		&operatorv1.CoreProviderList{},
+		&operatorv1.InfrastructureProviderList{},
+		&corev1.ConfigMapList{},
+	}
+
+	for _, list := range lists {
+		if err := r.List(ctx, list, client.InNamespace(r.ManagedNamespace), client.MatchingLabels{managedByLabel: fieldManager}); err != nil {
+			return fmt.Errorf("unable to list applied components: %w", err)
+		}
+
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return fmt.Errorf("unable to extract applied components: %w", err)
+		}
+
+		for _, item := range items {
+			obj, ok := item.(client.Object)
+			if !ok {
+				return fmt.Errorf("unable to convert to client object")
+			}
+
+			key, err := r.componentKey(obj)
+			if err != nil {
+				return err
+			}
+
+			if desiredKeys[key] || !obj.GetDeletionTimestamp().IsZero() {
+				continue
+			}
+
+			if _, ok := obj.GetAnnotations()[preventPruningAnnotation]; ok {
+				log.Info("component is no longer part of the assets but is protected from pruning", "component", key)
+				continue
+			}
+
+			log.Info("pruning component that is no longer part of the assets", "component", key, "sourceAsset", obj.GetAnnotations()[assets.SourceAssetAnnotation])
+			if err := r.Delete(ctx, obj); err != nil && !k8serrors.IsNotFound(err) {
+				return fmt.Errorf("unable to prune %s: %w", key, err)
+			}
+		}
+	}
+
 	return nil
 }
 
-// containerCustomizationFromProvider returns a list of containers customized for the given provider
-func (r *ClusterOperatorReconciler) containerCustomizationFromProvider(kind, name string, containers []operatorv1.ContainerSpec) []operatorv1.ContainerSpec {
+func (r *ClusterOperatorReconciler) componentKey(obj client.Object) (string, error) {
+	gvk, err := apiutil.GVKForObject(obj, r.Client.Scheme())
+	if err != nil {
+		return "", fmt.Errorf("unable to get GroupVersionKind for object: %w", err)
+	}
+
+	return fmt.Sprintf("%s/%s/%s", gvk.Kind, obj.GetNamespace(), obj.GetName()), nil
+}
+
+// componentsStatus returns the status of every Deployment shipped in the components of the applied provider ConfigMaps.
+// A Deployment that was not created yet is reported as not available.
+func (r *ClusterOperatorReconciler) componentsStatus(ctx context.Context, components []client.Object) ([]operatorstatus.ComponentStatus, error) {
+	statuses := []operatorstatus.ComponentStatus{}
+
+	for _, obj := range components {
+		cm, ok := obj.(*corev1.ConfigMap)
+		if !ok {
+			continue
+		}
+
+		deployments, err := deploymentsFromComponents(cm.Data[componentsConfigMapKey])
+		if err != nil {
+			return nil, fmt.Errorf("unable to read deployments from ConfigMap %s: %v", cm.Name, err)
+		}
+
+		for _, key := range deployments {
+			if key.Namespace == "" {
+				key.Namespace = r.ManagedNamespace
+			}
+
+			deployment := &appsv1.Deployment{}
+			if err := r.Get(ctx, key, deployment); k8serrors.IsNotFound(err) {
+				statuses = append(statuses, operatorstatus.ComponentStatus{
+					Name:    fmt.Sprintf("Deployment/%s", key.Name),
+					Message: "deployment does not exist yet",
+				})
+				continue
+			} else if err != nil {
+				return nil, fmt.Errorf("unable to get deployment %s: %v", key, err)
+			}
+
+			pods, err := r.deploymentPods(ctx, deployment)
+			if err != nil {
+				return nil, err
+			}
+
+			statuses = append(statuses, operatorstatus.DeploymentComponentStatus(deployment, pods...))
+		}
+	}
+
+	return statuses, nil
+}
+
+// deploymentPods returns the pods selected by the given Deployment.
+func (r *ClusterOperatorReconciler) deploymentPods(ctx context.Context, deployment *appsv1.Deployment) ([]corev1.Pod, error) {
+	if deployment.Spec.Selector == nil {
+		return nil, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse selector of deployment %s: %v", deployment.Name, err)
+	}
+
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods, client.InNamespace(deployment.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, fmt.Errorf("unable to list pods of deployment %s: %v", deployment.Name, err)
+	}
+
+	return pods.Items, nil
+}
+
+// deploymentsFromComponents returns the keys of the Deployments found in a multi-document components YAML.
+func deploymentsFromComponents(components string) ([]client.ObjectKey, error) {
+	keys := []client.ObjectKey{}
+
+	decoder := yaml.NewYAMLOrJSONDecoder(strings.NewReader(components), 4096)
+	for {
+		obj := &metav1.PartialObjectMetadata{}
+		if err := decoder.Decode(obj); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		if obj.Kind == "Deployment" {
+			keys = append(keys, client.ObjectKey{Namespace: obj.Namespace, Name: obj.Name})
+		}
+	}
+
+	return keys, nil
+}
+
+// containerCustomizationFromProvider returns a list of containers customized for the given provider.
+// The proxy environment is set on the manager container.
+func (r *ClusterOperatorReconciler) containerCustomizationFromProvider(kind, name string, containers []operatorv1.ContainerSpec, proxyEnv []corev1.EnvVar) []operatorv1.ContainerSpec {
 	for i := range containers {
 		switch containers[i].Name {
 		// We expect provider to always have a manager container
 		case "manager":
 			// TODO: we should return error when image was not found
 			image := getProviderImage(kind, name, r.Images)
+			if override, ok := r.ImageOverrides[name]; ok {
+				image = override
+			}
 			containers[i].Image = newImageMeta(image)
+			containers[i].Env = mergeEnv(containers[i].Env, proxyEnv)
 		case "kube-rbac-proxy":
 			image := r.Images["kube-rbac-proxy"]
+			if override, ok := r.ImageOverrides["kube-rbac-proxy"]; ok {
+				image = override
+			}
 			containers[i].Image = newImageMeta(image)
 		}
 	}
 	return containers
 }
 
+// proxyEnvironment returns the proxy environment variables from the status of the cluster-wide proxy.
+// Only the variables of the configured proxies are returned.
+func (r *ClusterOperatorReconciler) proxyEnvironment(ctx context.Context) ([]corev1.EnvVar, error) {
+	proxy := &configv1.Proxy{}
+	if err := r.Get(ctx, client.ObjectKey{Name: controllers.ProxyResourceName}, proxy); k8serrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to get cluster-wide proxy: %v", err)
+	}
+
+	env := []corev1.EnvVar{}
+	for _, v := range []corev1.EnvVar{
+		{Name: "HTTP_PROXY", Value: proxy.Status.HTTPProxy},
+		{Name: "HTTPS_PROXY", Value: proxy.Status.HTTPSProxy},
+		{Name: "NO_PROXY", Value: proxy.Status.NoProxy},
+	} {
+		if v.Value != "" {
+			env = append(env, v)
+		}
+	}
+
+	return env, nil
+}
+
+// mergeEnv sets the given environment variables on top of the existing ones, replacing variables with the same name.
+func mergeEnv(existing, env []corev1.EnvVar) []corev1.EnvVar {
+	for _, v := range env {
+		found := false
+		for i := range existing {
+			if existing[i].Name == v.Name {
+				existing[i] = v
+				found = true
+				break
+			}
+		}
+		if !found {
+			existing = append(existing, v)
+		}
+	}
+	return existing
+}
+
 func getProviderImage(kind, name string, images map[string]string) string {
 	expectedImage := ""
 	switch kind {
@@ -88,12 +505,19 @@ func getProviderImage(kind, name string, images map[string]string) string {
 func newImageMeta(imageURL string) *operatorv1.ImageMeta {
 	im := &operatorv1.ImageMeta{}
 
-	urlSplit := strings.Split(imageURL, ":")
-	if len(urlSplit) == 2 {
-		im.Tag = urlSplit[1]
+	// The registry host may have a port, so the tag or digest is only looked up after the last path separator
+	name := imageURL
+	if i := strings.LastIndex(imageURL, "/"); i >= 0 {
+		im.Repository = imageURL[:i]
+		name = imageURL[i+1:]
+	}
+
+	// Digests are kept in the name, e.g. bar@sha256 with the digest as tag, as the image URL is built from name:tag
+	if i := strings.LastIndex(name, ":"); i >= 0 {
+		im.Tag = name[i+1:]
+		name = name[:i]
 	}
-	urlSplit = strings.Split(urlSplit[0], "/")
-	im.Name = urlSplit[len(urlSplit)-1]
-	im.Repository = strings.Join(urlSplit[0:len(urlSplit)-1], "/")
+	im.Name = name
+
 	return im
 }
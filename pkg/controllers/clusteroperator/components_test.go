@@ -5,11 +5,16 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
 	operatorv1 "sigs.k8s.io/cluster-api-operator/api/v1alpha1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	configv1 "github.com/openshift/api/config/v1"
 	"github.com/openshift/cluster-capi-operator/pkg/controllers"
 	"github.com/openshift/cluster-capi-operator/pkg/operatorstatus"
 	"github.com/openshift/cluster-capi-operator/pkg/test"
@@ -28,6 +33,7 @@ var (
 
 var _ = Describe("Reconcile components", func() {
 	var r *ClusterOperatorReconciler
+	var rec *record.FakeRecorder
 
 	ctx := context.Background()
 	providerSpec := operatorv1.ProviderSpec{
@@ -47,9 +53,11 @@ var _ = Describe("Reconcile components", func() {
 	}
 
 	BeforeEach(func() {
+		rec = record.NewFakeRecorder(32)
 		r = &ClusterOperatorReconciler{
 			ClusterOperatorStatusClient: operatorstatus.ClusterOperatorStatusClient{
-				Client: cl,
+				Client:   cl,
+				Recorder: rec,
 			},
 			Images: map[string]string{
 				operatorImageName:               operatorImageSource,
@@ -93,16 +101,34 @@ var _ = Describe("Reconcile components", func() {
 		})
 
 		It("should create core provider and modify container images", func() {
-			Expect(r.reconcileCoreProvider(ctx, coreProvider)).To(Succeed())
+			Expect(r.reconcileCoreProvider(ctx, coreProvider, nil)).To(Succeed())
 		})
 
 		It("should update an existing core provider", func() {
 			Expect(cl.Create(ctx, coreProvider)).To(Succeed())
 			coreProvider.TypeMeta.Kind = "CoreProvider" // kind gets erased after Create()
 			coreProvider.Spec.Version = "v2.0.0"
-			Expect(r.reconcileCoreProvider(ctx, coreProvider)).To(Succeed())
+			Expect(r.reconcileCoreProvider(ctx, coreProvider, nil)).To(Succeed())
 			Expect(coreProvider.Spec.Version).To(Equal("v2.0.0"))
 		})
+
+		It("should preserve fields set by other managers when re-applied", func() {
+			desiredCoreProvider := coreProvider.DeepCopy()
+			Expect(r.reconcileCoreProvider(ctx, coreProvider, nil)).To(Succeed())
+
+			By("Setting replicas using a different field manager")
+			existing := &operatorv1.CoreProvider{}
+			Expect(cl.Get(ctx, client.ObjectKeyFromObject(coreProvider), existing)).To(Succeed())
+			existingCopy := existing.DeepCopy()
+			existing.Spec.Deployment.Replicas = pointer.Int(3)
+			Expect(cl.Patch(ctx, existing, client.MergeFrom(existingCopy), client.FieldOwner("other-manager"))).To(Succeed())
+
+			By("Re-applying the core provider")
+			Expect(r.reconcileCoreProvider(ctx, desiredCoreProvider, nil)).To(Succeed())
+
+			Expect(cl.Get(ctx, client.ObjectKeyFromObject(coreProvider), existing)).To(Succeed())
+			Expect(existing.Spec.Deployment.Replicas).To(HaveValue(Equal(3)))
+		})
 	})
 
 	Context("reconcile infrastructure provider", func() { // nolint:dupl
@@ -138,14 +164,14 @@ var _ = Describe("Reconcile components", func() {
 		})
 
 		It("should create infra provider and modify container images", func() {
-			Expect(r.reconcileInfrastructureProvider(ctx, infraProvider)).To(Succeed())
+			Expect(r.reconcileInfrastructureProvider(ctx, infraProvider, nil)).To(Succeed())
 		})
 
 		It("should update an existing infra provider", func() {
 			Expect(cl.Create(ctx, infraProvider)).To(Succeed())
 			infraProvider.TypeMeta.Kind = "InfrastructureProvider" // kind gets erased after Create()
 			infraProvider.Spec.Version = "v2.0.0"
-			Expect(r.reconcileInfrastructureProvider(ctx, infraProvider)).To(Succeed())
+			Expect(r.reconcileInfrastructureProvider(ctx, infraProvider, nil)).To(Succeed())
 			Expect(infraProvider.Spec.Version).To(Equal("v2.0.0"))
 		})
 	})
@@ -191,6 +217,128 @@ var _ = Describe("Reconcile components", func() {
 			Expect(cm.Data).To(HaveKeyWithValue("foo", "baz"))
 		})
 	})
+
+	Context("apply conflicts", func() {
+		var deployment *appsv1.Deployment
+
+		newDeployment := func(image string) *appsv1.Deployment {
+			return &appsv1.Deployment{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "Deployment",
+					APIVersion: appsv1.SchemeGroupVersion.String(),
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "capi-controller-manager",
+					Namespace: controllers.DefaultManagedNamespace,
+				},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: pointer.Int32(1),
+					Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"control-plane": "controller-manager"}},
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"control-plane": "controller-manager"}},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{{Name: "manager", Image: image}},
+						},
+					},
+				},
+			}
+		}
+
+		BeforeEach(func() {
+			deployment = newDeployment("capi:v1")
+		})
+
+		AfterEach(func() {
+			Expect(test.CleanupAndWait(ctx, cl, deployment)).To(Succeed())
+		})
+
+		It("should keep the replicas set by another manager when the manifest is re-applied", func() {
+			Expect(r.applyObject(ctx, deployment.DeepCopy())).To(Succeed())
+
+			By("Scaling the deployment using a different field manager")
+			existing := &appsv1.Deployment{}
+			Expect(cl.Get(ctx, client.ObjectKeyFromObject(deployment), existing)).To(Succeed())
+			existing.Spec.Replicas = pointer.Int32(3)
+			Expect(cl.Update(ctx, existing, client.FieldOwner("other-manager"))).To(Succeed())
+
+			By("Re-applying the deployment manifest with a new image")
+			Expect(r.applyObject(ctx, newDeployment("capi:v2"))).To(Succeed())
+
+			Expect(cl.Get(ctx, client.ObjectKeyFromObject(deployment), existing)).To(Succeed())
+			Expect(existing.Spec.Replicas).To(HaveValue(BeEquivalentTo(3)))
+			Expect(existing.Spec.Template.Spec.Containers[0].Image).To(Equal("capi:v2"))
+			Expect(rec.Events).To(Receive(And(ContainSubstring(applyConflictReason), ContainSubstring(".spec.replicas"))))
+		})
+	})
+})
+
+var _ = Describe("Prune components", func() {
+	var r *ClusterOperatorReconciler
+	var currentCM, staleCM, protectedCM *corev1.ConfigMap
+
+	ctx := context.Background()
+
+	newConfigMap := func(name string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: controllers.DefaultManagedNamespace,
+			},
+			Data: map[string]string{"components": name},
+		}
+	}
+
+	BeforeEach(func() {
+		r = &ClusterOperatorReconciler{
+			ClusterOperatorStatusClient: operatorstatus.ClusterOperatorStatusClient{
+				Client:           cl,
+				Recorder:         record.NewFakeRecorder(32),
+				ManagedNamespace: controllers.DefaultManagedNamespace,
+			},
+		}
+
+		currentCM = newConfigMap("current-components")
+		staleCM = newConfigMap("stale-components")
+		protectedCM = newConfigMap("protected-components")
+		protectedCM.SetAnnotations(map[string]string{preventPruningAnnotation: ""})
+
+		By("Applying the initial set of components")
+		Expect(r.reconcileConfigMap(ctx, currentCM.DeepCopy())).To(Succeed())
+		Expect(r.reconcileConfigMap(ctx, staleCM.DeepCopy())).To(Succeed())
+		Expect(r.reconcileConfigMap(ctx, protectedCM.DeepCopy())).To(Succeed())
+		Expect(r.pruneComponents(ctx, []client.Object{currentCM, staleCM, protectedCM})).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(test.CleanupAndWait(ctx, cl, currentCM, staleCM, protectedCM)).To(Succeed())
+	})
+
+	It("should label applied components", func() {
+		Expect(cl.Get(ctx, client.ObjectKeyFromObject(staleCM), staleCM)).To(Succeed())
+		Expect(staleCM.Labels).To(HaveKeyWithValue(managedByLabel, fieldManager))
+	})
+
+	It("should prune components removed from the assets", func() {
+		By("Applying a set of components without the stale and protected ones")
+		Expect(r.reconcileConfigMap(ctx, currentCM.DeepCopy())).To(Succeed())
+		Expect(r.pruneComponents(ctx, []client.Object{currentCM})).To(Succeed())
+
+		err := cl.Get(ctx, client.ObjectKeyFromObject(staleCM), &corev1.ConfigMap{})
+		Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+
+		Expect(cl.Get(ctx, client.ObjectKeyFromObject(currentCM), &corev1.ConfigMap{})).To(Succeed())
+		Expect(cl.Get(ctx, client.ObjectKeyFromObject(protectedCM), &corev1.ConfigMap{})).To(Succeed())
+	})
+
+	It("should not prune objects that were not applied by the operator", func() {
+		unmanagedCM := newConfigMap("unmanaged-components")
+		Expect(cl.Create(ctx, unmanagedCM)).To(Succeed())
+
+		Expect(r.pruneComponents(ctx, []client.Object{currentCM})).To(Succeed())
+		Expect(cl.Get(ctx, client.ObjectKeyFromObject(unmanagedCM), &corev1.ConfigMap{})).To(Succeed())
+
+		Expect(test.CleanupAndWait(ctx, cl, unmanagedCM)).To(Succeed())
+	})
 })
 
 var _ = Describe("New image meta", func() {
@@ -214,6 +362,27 @@ var _ = Describe("New image meta", func() {
 		Expect(imageMeta.Name).To(Equal("bar@sha256"))
 		Expect(imageMeta.Tag).To(Equal("baz"))
 	})
+
+	It("should parse an image name from a registry with a port and a tag", func() {
+		imageMeta := newImageMeta("mirror.example.com:5000/capi/aws:v2.0.2")
+		Expect(imageMeta.Repository).To(Equal("mirror.example.com:5000/capi"))
+		Expect(imageMeta.Name).To(Equal("aws"))
+		Expect(imageMeta.Tag).To(Equal("v2.0.2"))
+	})
+
+	It("should parse an image name from a registry with a port and a digest", func() {
+		imageMeta := newImageMeta("mirror.example.com:5000/capi/aws@sha256:baz")
+		Expect(imageMeta.Repository).To(Equal("mirror.example.com:5000/capi"))
+		Expect(imageMeta.Name).To(Equal("aws@sha256"))
+		Expect(imageMeta.Tag).To(Equal("baz"))
+	})
+
+	It("should parse an image name from a registry with a port without a tag", func() {
+		imageMeta := newImageMeta("mirror.example.com:5000/aws")
+		Expect(imageMeta.Repository).To(Equal("mirror.example.com:5000"))
+		Expect(imageMeta.Name).To(Equal("aws"))
+		Expect(imageMeta.Tag).To(BeEmpty())
+	})
 })
 
 var _ = Describe("Container customization for provider", func() {
@@ -233,7 +402,7 @@ var _ = Describe("Container customization for provider", func() {
 				{
 					Name: "manager",
 				},
-			})
+			}, nil)
 		Expect(containers).To(HaveLen(1))
 		Expect(containers[0].Name).To(Equal("manager"))
 		Expect(containers[0].Image.Name).To(Equal("cluster-api"))
@@ -251,7 +420,7 @@ var _ = Describe("Container customization for provider", func() {
 				{
 					Name: "kube-rbac-proxy",
 				},
-			})
+			}, nil)
 
 		Expect(containers).To(HaveLen(2))
 		Expect(containers[0].Name).To(Equal("manager"))
@@ -264,4 +433,161 @@ var _ = Describe("Container customization for provider", func() {
 		Expect(containers[1].Image.Repository).To(Equal("test.com"))
 		Expect(containers[1].Image.Tag).To(Equal("tag"))
 	})
+
+	Context("with image overrides", func() {
+		var overrideReconciler *ClusterOperatorReconciler
+
+		BeforeEach(func() {
+			overrideReconciler = &ClusterOperatorReconciler{
+				Images: reconciler.Images,
+				ImageOverrides: map[string]string{
+					"aws":             "mirror.com/aws-override:v2",
+					"kube-rbac-proxy": "mirror.com/rbac-override:v2",
+					"unknown":         "mirror.com/unknown:v2",
+				},
+			}
+		})
+
+		It("should use overridden images for the provider", func() {
+			containers := overrideReconciler.containerCustomizationFromProvider(
+				"InfrastructureProvider",
+				"aws",
+				[]operatorv1.ContainerSpec{
+					{
+						Name: "manager",
+					},
+					{
+						Name: "kube-rbac-proxy",
+					},
+				}, nil)
+
+			Expect(containers).To(HaveLen(2))
+			Expect(containers[0].Image.Name).To(Equal("aws-override"))
+			Expect(containers[0].Image.Repository).To(Equal("mirror.com"))
+			Expect(containers[0].Image.Tag).To(Equal("v2"))
+
+			Expect(containers[1].Image.Name).To(Equal("rbac-override"))
+			Expect(containers[1].Image.Repository).To(Equal("mirror.com"))
+			Expect(containers[1].Image.Tag).To(Equal("v2"))
+		})
+
+		It("should keep default images for providers without an override", func() {
+			containers := overrideReconciler.containerCustomizationFromProvider(
+				"CoreProvider",
+				"cluster-api",
+				[]operatorv1.ContainerSpec{
+					{
+						Name: "manager",
+					},
+				}, nil)
+
+			Expect(containers).To(HaveLen(1))
+			Expect(containers[0].Image.Name).To(Equal("cluster-api"))
+			Expect(containers[0].Image.Repository).To(Equal("test.com"))
+			Expect(containers[0].Image.Tag).To(Equal("tag"))
+		})
+	})
+})
+
+var _ = Describe("Cluster-wide proxy", func() {
+	ctx := context.Background()
+	var r *ClusterOperatorReconciler
+	var proxy *configv1.Proxy
+
+	BeforeEach(func() {
+		r = &ClusterOperatorReconciler{
+			ClusterOperatorStatusClient: operatorstatus.ClusterOperatorStatusClient{
+				Client: cl,
+			},
+			Images: map[string]string{
+				infrastructureProviderImageName: infrastructureProviderImageSource,
+			},
+		}
+
+		proxy = &configv1.Proxy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: controllers.ProxyResourceName,
+			},
+		}
+	})
+
+	AfterEach(func() {
+		Expect(test.CleanupAndWait(ctx, cl, proxy)).To(Succeed())
+	})
+
+	It("should return no environment when the proxy does not exist", func() {
+		env, err := r.proxyEnvironment(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(env).To(BeEmpty())
+	})
+
+	It("should return the environment of the configured proxies", func() {
+		Expect(cl.Create(ctx, proxy)).To(Succeed())
+		proxy.Status = configv1.ProxyStatus{
+			HTTPSProxy: "https://proxy.example.com:3128",
+			NoProxy:    ".cluster.local,10.0.0.0/16",
+		}
+		Expect(cl.Status().Update(ctx, proxy)).To(Succeed())
+
+		env, err := r.proxyEnvironment(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(env).To(Equal([]corev1.EnvVar{
+			{Name: "HTTPS_PROXY", Value: "https://proxy.example.com:3128"},
+			{Name: "NO_PROXY", Value: ".cluster.local,10.0.0.0/16"},
+		}))
+	})
+
+	It("should set the proxy environment on the manager container only", func() {
+		proxyEnv := []corev1.EnvVar{
+			{Name: "HTTPS_PROXY", Value: "https://proxy.example.com:3128"},
+		}
+
+		containers := r.containerCustomizationFromProvider(
+			"InfrastructureProvider",
+			"aws",
+			[]operatorv1.ContainerSpec{
+				{
+					Name: "manager",
+					Env: []corev1.EnvVar{
+						{Name: "AWS_SHARED_CREDENTIALS_FILE", Value: "/home/.aws/credentials"},
+						{Name: "HTTPS_PROXY", Value: "https://stale.example.com:3128"},
+					},
+				},
+				{
+					Name: "kube-rbac-proxy",
+				},
+			}, proxyEnv)
+
+		Expect(containers[0].Env).To(Equal([]corev1.EnvVar{
+			{Name: "AWS_SHARED_CREDENTIALS_FILE", Value: "/home/.aws/credentials"},
+			{Name: "HTTPS_PROXY", Value: "https://proxy.example.com:3128"},
+		}))
+		Expect(containers[1].Env).To(BeEmpty())
+	})
+})
+
+var _ = Describe("Deployments from components", func() {
+	It("should return only the deployments of a multi-document components YAML", func() {
+		keys, err := deploymentsFromComponents(`apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: capi-manager
+  namespace: openshift-cluster-api
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: capi-controller-manager
+  namespace: openshift-cluster-api
+spec:
+  replicas: 1
+`)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(keys).To(ConsistOf(client.ObjectKey{Namespace: "openshift-cluster-api", Name: "capi-controller-manager"}))
+	})
+
+	It("should return an error for malformed components", func() {
+		_, err := deploymentsFromComponents("kind: [")
+		Expect(err).To(HaveOccurred())
+	})
 })
@@ -1,6 +1,9 @@
 package clusteroperator
 
 import (
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
@@ -9,6 +12,7 @@ import (
 
 	configv1 "github.com/openshift/api/config/v1"
 	"github.com/openshift/cluster-capi-operator/pkg/controllers"
+	"github.com/openshift/cluster-capi-operator/pkg/util"
 )
 
 func toClusterOperator(client.Object) []reconcile.Request {
@@ -44,3 +48,125 @@ func infrastructurePredicates() predicate.Funcs {
 		DeleteFunc:  func(e event.DeleteEvent) bool { return isInfrastructureCluster(e.Object) },
 	}
 }
+
+func proxyPredicates() predicate.Funcs {
+	isClusterProxy := func(obj runtime.Object) bool {
+		proxy, ok := obj.(*configv1.Proxy)
+		return ok && proxy.GetName() == controllers.ProxyResourceName
+	}
+
+	return predicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return isClusterProxy(e.Object) },
+		UpdateFunc:  func(e event.UpdateEvent) bool { return isClusterProxy(e.ObjectNew) },
+		GenericFunc: func(e event.GenericEvent) bool { return isClusterProxy(e.Object) },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return isClusterProxy(e.Object) },
+	}
+}
+
+func isAppliedComponent(obj client.Object) bool {
+	return obj.GetLabels()[managedByLabel] == fieldManager
+}
+
+// providerPredicates filters providers applied by the operator, ignoring status only updates.
+func providerPredicates() predicate.Predicate {
+	return predicate.And(
+		predicate.NewPredicateFuncs(isAppliedComponent),
+		predicate.Or(
+			predicate.GenerationChangedPredicate{},
+			predicate.LabelChangedPredicate{},
+			predicate.AnnotationChangedPredicate{},
+		),
+	)
+}
+
+// configMapPredicates filters ConfigMaps applied by the operator and the provider overrides ConfigMap.
+// ConfigMaps have no status, so every update is relevant.
+func configMapPredicates(namespace string) predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return isAppliedComponent(obj) || util.IsProviderOverridesConfigMap(obj, namespace)
+	})
+}
+
+// deploymentPredicates filters provider Deployments in the managed namespace. Status updates are only relevant
+// when the Deployment conditions driving the reported component status change.
+func deploymentPredicates(namespace string) predicate.Predicate {
+	return predicate.And(
+		predicate.NewPredicateFuncs(func(obj client.Object) bool {
+			_, ok := obj.GetLabels()[providerLabel]
+			return ok && obj.GetNamespace() == namespace
+		}),
+		predicate.Funcs{
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				oldDeployment, okOld := e.ObjectOld.(*appsv1.Deployment)
+				newDeployment, okNew := e.ObjectNew.(*appsv1.Deployment)
+				if !okOld || !okNew {
+					return true
+				}
+
+				return !equality.Semantic.DeepEqual(deploymentConditions(oldDeployment), deploymentConditions(newDeployment))
+			},
+		},
+	)
+}
+
+// podPredicates filters provider pods in the managed namespace, so container failures of the providers are reported
+// as soon as the kubelet observes them. The pod templates of the provider Deployments carry the provider label.
+// Updates are only relevant when the waiting reason or readiness of a container changes.
+func podPredicates(namespace string) predicate.Predicate {
+	return predicate.And(
+		predicate.NewPredicateFuncs(func(obj client.Object) bool {
+			_, ok := obj.GetLabels()[providerLabel]
+			return ok && obj.GetNamespace() == namespace
+		}),
+		predicate.Funcs{
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				oldPod, okOld := e.ObjectOld.(*corev1.Pod)
+				newPod, okNew := e.ObjectNew.(*corev1.Pod)
+				if !okOld || !okNew {
+					return true
+				}
+
+				return !equality.Semantic.DeepEqual(containerStates(oldPod), containerStates(newPod))
+			},
+		},
+	)
+}
+
+// deploymentCondition is the part of a Deployment condition used for the component status.
+type deploymentCondition struct {
+	Type    appsv1.DeploymentConditionType
+	Status  corev1.ConditionStatus
+	Reason  string
+	Message string
+}
+
+// deploymentConditions returns the Deployment conditions without their timestamps, which change on every status update.
+func deploymentConditions(deployment *appsv1.Deployment) []deploymentCondition {
+	conds := []deploymentCondition{}
+	for _, cond := range deployment.Status.Conditions {
+		conds = append(conds, deploymentCondition{Type: cond.Type, Status: cond.Status, Reason: cond.Reason, Message: cond.Message})
+	}
+
+	return conds
+}
+
+// containerState is the part of a container status used for the component status.
+type containerState struct {
+	Name          string
+	Ready         bool
+	WaitingReason string
+}
+
+// containerStates returns the waiting reason and readiness of the init and regular containers of the pod.
+func containerStates(pod *corev1.Pod) []containerState {
+	states := []containerState{}
+	for _, status := range append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...) {
+		state := containerState{Name: status.Name, Ready: status.Ready}
+		if status.State.Waiting != nil {
+			state.WaitingReason = status.State.Waiting.Reason
+		}
+		states = append(states, state)
+	}
+
+	return states
+}
@@ -5,4 +5,6 @@ const (
 	OperatorVersionKey         = "operator"
 	ClusterOperatorName        = "cluster-api"
 	InfrastructureResourceName = "cluster"
+	ProxyResourceName          = "cluster"
+	MachineAPINamespace        = "openshift-machine-api"
 )
@@ -2,7 +2,9 @@ package kubeconfig
 
 import (
 	"errors"
+	"fmt"
 
+	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/tools/clientcmd/api"
 
 	"github.com/openshift/cluster-capi-operator/pkg/controllers"
@@ -57,3 +59,18 @@ func generateKubeconfig(options kubeconfigOptions) (*api.Config, error) {
 
 	return kubeconfig, nil
 }
+
+// renderKubeconfig generates the kubeconfig and serializes it to YAML.
+func renderKubeconfig(options kubeconfigOptions) ([]byte, error) {
+	kubeconfig, err := generateKubeconfig(options)
+	if err != nil {
+		return nil, fmt.Errorf("error generating kubeconfig: %v", err)
+	}
+
+	out, err := clientcmd.Write(*kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("error writing kubeconfig: %v", err)
+	}
+
+	return out, nil
+}
@@ -1,8 +1,11 @@
 package kubeconfig
 
 import (
+	"encoding/base64"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"k8s.io/client-go/tools/clientcmd"
 
 	"github.com/openshift/cluster-capi-operator/pkg/controllers"
 )
@@ -38,6 +41,36 @@ var _ = Describe("Generate kubeconfig", func() {
 		Expect(kubeconfig.AuthInfos["cluster-capi-operator"].Token).To(Equal(testBase64Text))
 	})
 
+	It("should render kubeconfig YAML embedding the CA bundle", func() {
+		out, err := renderKubeconfig(*options)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(string(out)).To(ContainSubstring("certificate-authority-data: " + base64.StdEncoding.EncodeToString(options.caCert)))
+		Expect(string(out)).To(ContainSubstring("server: https://example.com"))
+		Expect(string(out)).To(ContainSubstring("token: " + testBase64Text))
+		Expect(string(out)).To(ContainSubstring("current-context: test"))
+
+		kubeconfig, err := clientcmd.Load(out)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kubeconfig.Clusters[options.clusterName].CertificateAuthorityData).To(Equal(options.caCert))
+	})
+
+	It("should render kubeconfig YAML with a custom CA bundle", func() {
+		options.caCert = []byte("-----BEGIN CERTIFICATE-----\ncustom\n-----END CERTIFICATE-----\n")
+		out, err := renderKubeconfig(*options)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(string(out)).To(ContainSubstring("certificate-authority-data: " + base64.StdEncoding.EncodeToString(options.caCert)))
+		Expect(string(out)).NotTo(ContainSubstring("certificate-authority:"))
+	})
+
+	It("should fail to render with empty ca cert", func() {
+		options.caCert = nil
+		out, err := renderKubeconfig(*options)
+		Expect(err).To((HaveOccurred()))
+		Expect(out).To(BeNil())
+	})
+
 	It("should fail with empty token", func() {
 		options.token = nil
 		kubeconfig, err := generateKubeconfig(*options)
@@ -2,16 +2,19 @@ package kubeconfig
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"strings"
 	"time"
 
+	authenticationv1 "k8s.io/api/authentication/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
-	"k8s.io/client-go/tools/clientcmd"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
@@ -23,10 +26,34 @@ import (
 	configv1 "github.com/openshift/api/config/v1"
 	"github.com/openshift/cluster-capi-operator/pkg/controllers"
 	"github.com/openshift/cluster-capi-operator/pkg/operatorstatus"
+	"github.com/openshift/cluster-capi-operator/pkg/util"
 )
 
 const (
-	tokenSecretName = "cluster-capi-operator-secret" // nolint
+	// DefaultTokenExpiration is the lifetime requested for the kubeconfig token when none is configured.
+	DefaultTokenExpiration = time.Hour
+
+	// DefaultTokenRotationFraction is the fraction of the token lifetime after which the token is rotated when none is configured.
+	DefaultTokenRotationFraction = 0.5
+
+	// MinTokenExpiration is the shortest token lifetime accepted by the TokenRequest API.
+	MinTokenExpiration = 10 * time.Minute
+
+	serviceAccountName = "cluster-capi-operator"
+	caConfigMapName    = "kube-root-ca.crt"
+	caBundleKey        = "ca.crt"
+
+	tokenIssuedAnnotation       = "cluster-capi-operator.openshift.io/token-issued"
+	tokenExpirationAnnotation   = "cluster-capi-operator.openshift.io/token-expiration"
+	tokenAudiencesAnnotation    = "cluster-capi-operator.openshift.io/token-audiences"
+	serviceAccountUIDAnnotation = "cluster-capi-operator.openshift.io/service-account-uid"
+	caHashAnnotation            = "cluster-capi-operator.openshift.io/ca-hash"
+
+	// kubeconfigControllerName prefixes the ClusterOperator conditions reporting the state of the controller.
+	kubeconfigControllerName = "KubeconfigController"
+
+	kubeconfigCreatedReason = "KubeconfigCreated"
+	tokenRotatedReason      = "KubeconfigTokenRotated"
 )
 
 // ClusterReconciler reconciles a ClusterOperator object
@@ -35,19 +62,46 @@ type KubeconfigReconciler struct {
 	Scheme             *runtime.Scheme
 	RestCfg            *rest.Config
 	SupportedPlatforms map[string]bool
-	clusterName        string
+	// TokenExpiration is the lifetime requested for the kubeconfig token.
+	TokenExpiration time.Duration
+	// TokenRotationFraction is the fraction of the token lifetime after which the token is rotated.
+	TokenRotationFraction float64
+	// TokenAudiences are the audiences requested for the kubeconfig token. The API server audience is used when empty.
+	TokenAudiences []string
+	// CAConfigMap references the ConfigMap whose ca.crt is embedded in the kubeconfig.
+	// The kube-root-ca.crt ConfigMap of the managed namespace is used when empty.
+	CAConfigMap client.ObjectKey
+	clusterName string
+	// clientset requests the service account tokens. It is created from RestCfg in SetupWithManager.
+	clientset kubernetes.Interface
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *KubeconfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	clientset, err := kubernetes.NewForConfig(r.RestCfg)
+	if err != nil {
+		return fmt.Errorf("unable to create clientset: %v", err)
+	}
+	r.clientset = clientset
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(
-			&corev1.Secret{},
-			builder.WithPredicates(tokenSecretPredicate()),
+			&corev1.ServiceAccount{},
+			builder.WithPredicates(serviceAccountPredicate()),
+		).
+		Watches(
+			&source.Kind{Type: &corev1.ConfigMap{}},
+			handler.EnqueueRequestsFromMapFunc(toServiceAccount),
+			builder.WithPredicates(caConfigMapPredicate(r.caConfigMapKey())),
+		).
+		Watches(
+			&source.Kind{Type: &corev1.ConfigMap{}},
+			handler.EnqueueRequestsFromMapFunc(toServiceAccount),
+			builder.WithPredicates(providerOverridesConfigMapPredicate(r.ManagedNamespace)),
 		).
 		Watches(
 			&source.Kind{Type: &corev1.Secret{}},
-			handler.EnqueueRequestsFromMapFunc(toTokenSecret),
+			handler.EnqueueRequestsFromMapFunc(toServiceAccount),
 			builder.WithPredicates(kubeconfigSecretPredicate()),
 		).
 		Complete(r)
@@ -59,7 +113,7 @@ func (r *KubeconfigReconciler) Reconcile(ctx context.Context, _ ctrl.Request) (c
 	infra := &configv1.Infrastructure{}
 	if err := r.Get(ctx, client.ObjectKey{Name: controllers.InfrastructureResourceName}, infra); err != nil {
 		log.Error(err, "Unable to retrive Infrastructure object")
-		if err := r.SetStatusDegraded(ctx, err); err != nil {
+		if err := r.SetControllerDegraded(ctx, kubeconfigControllerName, err); err != nil {
 			return ctrl.Result{}, fmt.Errorf("error syncing ClusterOperatorStatus: %v", err)
 		}
 		return ctrl.Result{}, err
@@ -67,7 +121,7 @@ func (r *KubeconfigReconciler) Reconcile(ctx context.Context, _ ctrl.Request) (c
 
 	if infra.Status.PlatformStatus == nil {
 		log.Info("No platform status exists in infrastructure object. Skipping kubeconfig reconciliation...")
-		if err := r.SetStatusAvailable(ctx); err != nil {
+		if err := r.SetControllerAvailable(ctx, kubeconfigControllerName); err != nil {
 			return ctrl.Result{}, err
 		}
 		return ctrl.Result{}, nil
@@ -75,10 +129,18 @@ func (r *KubeconfigReconciler) Reconcile(ctx context.Context, _ ctrl.Request) (c
 
 	r.clusterName = infra.Status.InfrastructureName
 
-	// If the platform type is not supported, we should skip cluster reconciliation.
-	if _, ok := r.SupportedPlatforms[strings.ToLower(string(infra.Status.PlatformStatus.Type))]; !ok {
-		log.Info("Platform type is not supported. Skipping kubeconfig reconciliation...", "platformType", infra.Status.PlatformStatus.Type)
-		if err := r.SetStatusAvailable(ctx); err != nil {
+	// If the provider of the platform is not enabled, we should skip cluster reconciliation.
+	enabled, err := util.IsProviderEnabled(ctx, r.Client, r.ManagedNamespace, r.SupportedPlatforms, infra.Status.PlatformStatus.Type)
+	if err != nil {
+		log.Error(err, "Unable to determine if the infrastructure provider is enabled")
+		if err := r.SetControllerDegraded(ctx, kubeconfigControllerName, err); err != nil {
+			return ctrl.Result{}, fmt.Errorf("error syncing ClusterOperatorStatus: %v", err)
+		}
+		return ctrl.Result{}, err
+	}
+	if !enabled {
+		log.Info("Platform type is not supported or its provider is disabled. Skipping kubeconfig reconciliation...", "platformType", infra.Status.PlatformStatus.Type)
+		if err := r.SetControllerAvailable(ctx, kubeconfigControllerName); err != nil {
 			return ctrl.Result{}, err
 		}
 		return ctrl.Result{}, nil
@@ -89,66 +151,104 @@ func (r *KubeconfigReconciler) Reconcile(ctx context.Context, _ ctrl.Request) (c
 	res, err := r.reconcileKubeconfig(ctx)
 	if err != nil {
 		log.Error(err, "Error reconciling kubeconfig")
-		if err := r.SetStatusDegraded(ctx, err); err != nil {
+		if err := r.SetControllerDegraded(ctx, kubeconfigControllerName, err); err != nil {
 			return ctrl.Result{}, fmt.Errorf("error syncing ClusterOperatorStatus: %v", err)
 		}
 		return ctrl.Result{}, err
 	}
 
-	return res, r.SetStatusAvailable(ctx)
+	return res, r.SetControllerAvailable(ctx, kubeconfigControllerName)
 }
 
 func (r *KubeconfigReconciler) reconcileKubeconfig(ctx context.Context) (ctrl.Result, error) {
 	log := ctrl.LoggerFrom(ctx)
 
-	// Get the token secret
-	tokenSecret := &corev1.Secret{}
-	tokenSecretKey := client.ObjectKey{
-		Name:      tokenSecretName,
+	// Get the service account the token is requested for
+	serviceAccount := &corev1.ServiceAccount{}
+	serviceAccountKey := client.ObjectKey{
+		Name:      serviceAccountName,
 		Namespace: controllers.DefaultManagedNamespace,
 	}
-	if err := r.Get(ctx, tokenSecretKey, tokenSecret); err != nil {
+	if err := r.Get(ctx, serviceAccountKey, serviceAccount); err != nil {
+		if errors.IsNotFound(err) {
+			log.Info("Waiting for service account to be created")
+			return ctrl.Result{RequeueAfter: 1 * time.Minute}, nil
+		}
+		return ctrl.Result{}, fmt.Errorf("unable to retrieve ServiceAccount object: %v", err)
+	}
+
+	// Get the CA bundle used to verify the API server
+	caConfigMap := &corev1.ConfigMap{}
+	caConfigMapKey := r.caConfigMapKey()
+	if err := r.Get(ctx, caConfigMapKey, caConfigMap); err != nil {
 		if errors.IsNotFound(err) {
-			log.Info("Waiting for token secret to be created")
+			log.Info("Waiting for CA ConfigMap to be created")
 			return ctrl.Result{RequeueAfter: 1 * time.Minute}, nil
 		}
-		return ctrl.Result{}, fmt.Errorf("unable to retrieve Secret object: %v", err)
+		return ctrl.Result{}, fmt.Errorf("unable to retrieve ConfigMap object: %v", err)
+	}
+	caCert := []byte(caConfigMap.Data[caBundleKey])
+	if len(caCert) == 0 {
+		return ctrl.Result{}, fmt.Errorf("ConfigMap %s has no %s", caConfigMapKey, caBundleKey)
+	}
+	caHash := hashCA(caCert)
+
+	kubeconfigSecretKey := client.ObjectKey{
+		Name:      fmt.Sprintf("%s-kubeconfig", r.clusterName),
+		Namespace: controllers.DefaultManagedNamespace,
 	}
 
-	if time.Since(tokenSecret.CreationTimestamp.Time) >= 30*time.Minute {
-		log.Info("Token secret is older than 30 minutes. Recreating it...")
-		// The token secret is managed by the CVO, it should be recreated shortly after deletion.
-		if err := r.Delete(ctx, tokenSecret); err != nil {
-			return ctrl.Result{}, fmt.Errorf("unable to delete Secret object: %v", err)
+	rotationReason := ""
+	existingSecret := &corev1.Secret{}
+	if err := r.Get(ctx, kubeconfigSecretKey, existingSecret); err != nil && !errors.IsNotFound(err) {
+		return ctrl.Result{}, fmt.Errorf("unable to retrieve kubeconfig Secret object: %v", err)
+	} else if err == nil {
+		if expiresAt, err := time.Parse(time.RFC3339, existingSecret.Annotations[tokenExpirationAnnotation]); err == nil {
+			tokenExpiry.set(expiresAt)
 		}
-		return ctrl.Result{RequeueAfter: 1 * time.Minute}, nil
+
+		requeueAfter, rotate, reason := r.tokenRotation(existingSecret, serviceAccount, caHash)
+		if !rotate {
+			return ctrl.Result{RequeueAfter: requeueAfter}, nil
+		}
+		log.Info("Rotating kubeconfig token", "reason", reason)
+		rotationReason = reason
+	}
+
+	issuedAt := time.Now()
+	tokenRequest, err := r.requestToken(ctx, serviceAccount)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("unable to request token for ServiceAccount %s: %v", serviceAccountKey, err)
 	}
+	expiresAt := tokenRequest.Status.ExpirationTimestamp.Time
 
 	// Generate kubeconfig
-	kubeconfig, err := generateKubeconfig(kubeconfigOptions{
-		token:            tokenSecret.Data["token"],
-		caCert:           tokenSecret.Data["ca.crt"],
+	out, err := renderKubeconfig(kubeconfigOptions{
+		token:            []byte(tokenRequest.Status.Token),
+		caCert:           caCert,
 		apiServerEnpoint: r.RestCfg.Host,
 		clusterName:      r.clusterName,
 	})
-
 	if err != nil {
-		return ctrl.Result{}, fmt.Errorf("error generating kubeconfig: %v", err)
+		return ctrl.Result{}, err
 	}
 
 	// Create a secret with generated kubeconfig
-	out, err := clientcmd.Write(*kubeconfig)
-	if err != nil {
-		return ctrl.Result{}, fmt.Errorf("error writing kubeconfig: %v", err)
-	}
 
 	kubeconfigSecret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      fmt.Sprintf("%s-kubeconfig", r.clusterName),
-			Namespace: controllers.DefaultManagedNamespace,
+			Name:      kubeconfigSecretKey.Name,
+			Namespace: kubeconfigSecretKey.Namespace,
 			Labels: map[string]string{
 				clusterv1.ClusterLabelName: r.clusterName,
 			},
+			Annotations: map[string]string{
+				tokenIssuedAnnotation:       issuedAt.UTC().Format(time.RFC3339),
+				tokenExpirationAnnotation:   expiresAt.UTC().Format(time.RFC3339),
+				tokenAudiencesAnnotation:    strings.Join(r.TokenAudiences, ","),
+				serviceAccountUIDAnnotation: string(serviceAccount.UID),
+				caHashAnnotation:            caHash,
+			},
 		},
 		Data: map[string][]byte{
 			"value": out,
@@ -165,6 +265,117 @@ func (r *KubeconfigReconciler) reconcileKubeconfig(ctx context.Context) (ctrl.Re
 	}); err != nil {
 		return ctrl.Result{}, fmt.Errorf("error reconciling kubeconfig secret: %v", err)
 	}
+	tokenExpiry.set(expiresAt)
+
+	if rotationReason == "" {
+		r.Recorder.Eventf(kubeconfigSecret, corev1.EventTypeNormal, kubeconfigCreatedReason, "Created kubeconfig with a token expiring at %s", expiresAt.UTC().Format(time.RFC3339))
+	} else {
+		r.Recorder.Eventf(kubeconfigSecret, corev1.EventTypeNormal, tokenRotatedReason, "Rotated kubeconfig token because the %s, new token expires at %s", rotationReason, expiresAt.UTC().Format(time.RFC3339))
+	}
+
+	return ctrl.Result{RequeueAfter: tokenRequeueAfter(time.Now(), issuedAt, expiresAt, r.tokenExpiration(), r.tokenRotationFraction())}, nil
+}
+
+// tokenRotation reports whether the token in the existing kubeconfig secret has to be rotated now, and if not,
+// how long until it has to. The token is rotated early when it was issued for a different service account or
+// different audiences, or the CA bundle changed since.
+func (r *KubeconfigReconciler) tokenRotation(secret *corev1.Secret, serviceAccount *corev1.ServiceAccount, caHash string) (time.Duration, bool, string) {
+	annotations := secret.GetAnnotations()
+
+	if len(secret.Data["value"]) == 0 {
+		return 0, true, "kubeconfig secret has no kubeconfig"
+	}
+
+	if annotations[serviceAccountUIDAnnotation] != string(serviceAccount.UID) {
+		return 0, true, "service account changed"
+	}
+
+	if annotations[tokenAudiencesAnnotation] != strings.Join(r.TokenAudiences, ",") {
+		return 0, true, "token audiences changed"
+	}
+
+	if annotations[caHashAnnotation] != caHash {
+		return 0, true, "CA bundle changed"
+	}
+
+	issuedAt, err := time.Parse(time.RFC3339, annotations[tokenIssuedAnnotation])
+	if err != nil {
+		return 0, true, "token issue time is unknown"
+	}
+
+	expiresAt, err := time.Parse(time.RFC3339, annotations[tokenExpirationAnnotation])
+	if err != nil {
+		return 0, true, "token expiration time is unknown"
+	}
+
+	requeueAfter := tokenRequeueAfter(time.Now(), issuedAt, expiresAt, r.tokenExpiration(), r.tokenRotationFraction())
+	if requeueAfter <= 0 {
+		return 0, true, "token is due for rotation"
+	}
+
+	return requeueAfter, false, ""
+}
+
+// requestToken requests a new bound token for the service account using the TokenRequest API.
+func (r *KubeconfigReconciler) requestToken(ctx context.Context, serviceAccount *corev1.ServiceAccount) (*authenticationv1.TokenRequest, error) {
+	expirationSeconds := int64(r.tokenExpiration().Seconds())
+	tokenRequest := &authenticationv1.TokenRequest{
+		Spec: authenticationv1.TokenRequestSpec{
+			ExpirationSeconds: &expirationSeconds,
+			Audiences:         r.TokenAudiences,
+		},
+	}
+
+	return r.clientset.CoreV1().ServiceAccounts(serviceAccount.Namespace).CreateToken(ctx, serviceAccount.Name, tokenRequest, metav1.CreateOptions{})
+}
+
+// ParseConfigMapReference parses a ConfigMap reference in the namespace/name form.
+func ParseConfigMapReference(ref string) (client.ObjectKey, error) {
+	parts := strings.Split(ref, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return client.ObjectKey{}, fmt.Errorf("invalid ConfigMap reference %q, expected namespace/name", ref)
+	}
+
+	return client.ObjectKey{Namespace: parts[0], Name: parts[1]}, nil
+}
+
+func (r *KubeconfigReconciler) caConfigMapKey() client.ObjectKey {
+	if r.CAConfigMap.Name == "" {
+		return client.ObjectKey{Name: caConfigMapName, Namespace: controllers.DefaultManagedNamespace}
+	}
+	return r.CAConfigMap
+}
+
+func (r *KubeconfigReconciler) tokenExpiration() time.Duration {
+	if r.TokenExpiration == 0 {
+		return DefaultTokenExpiration
+	}
+	return r.TokenExpiration
+}
+
+func (r *KubeconfigReconciler) tokenRotationFraction() float64 {
+	if r.TokenRotationFraction <= 0 || r.TokenRotationFraction >= 1 {
+		return DefaultTokenRotationFraction
+	}
+	return r.TokenRotationFraction
+}
+
+// tokenRequeueAfter returns how long from now a token issued at issuedAt and expiring at expiresAt should be rotated,
+// once the given fraction of its lifetime has passed. A non positive result means the token is due for rotation.
+// The expiration is set by the API server clock, which can be skewed from ours, so its lifetime is never trusted to be
+// longer than requested, and the requested lifetime is used when the expiration is not after the issue time.
+func tokenRequeueAfter(now, issuedAt, expiresAt time.Time, requested time.Duration, fraction float64) time.Duration {
+	lifetime := expiresAt.Sub(issuedAt)
+	if lifetime <= 0 || lifetime > requested {
+		lifetime = requested
+	}
+
+	rotateAt := issuedAt.Add(time.Duration(float64(lifetime) * fraction))
+
+	return rotateAt.Sub(now)
+}
 
-	return ctrl.Result{}, nil
+func hashCA(caCert []byte) string {
+	sum := sha256.Sum256(caCert)
+	return hex.EncodeToString(sum[:])
 }
@@ -8,8 +8,10 @@ import (
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	"github.com/openshift/cluster-capi-operator/pkg/controllers"
 	"github.com/openshift/cluster-capi-operator/pkg/operatorstatus"
@@ -19,84 +21,226 @@ import (
 var _ = Describe("Reconcile kubeconfig secret", func() {
 	Context("create or update kubeconfig secret", func() {
 		var r *KubeconfigReconciler
-		var tokenSecret *corev1.Secret
-		kubeconfigSecret := &corev1.Secret{}
+		var rec *record.FakeRecorder
+		var serviceAccount *corev1.ServiceAccount
+		var caConfigMap *corev1.ConfigMap
+		var kubeconfigSecret *corev1.Secret
+		var kubeconfigSecretKey client.ObjectKey
 
 		BeforeEach(func() {
+			rec = record.NewFakeRecorder(100)
 			r = &KubeconfigReconciler{
 				ClusterOperatorStatusClient: operatorstatus.ClusterOperatorStatusClient{
-					Client: cl,
+					Client:   cl,
+					Recorder: rec,
 				},
 				clusterName: "test-cluster",
 				RestCfg:     cfg,
+				clientset:   kubernetes.NewForConfigOrDie(cfg),
 			}
 
-			tokenSecret = &corev1.Secret{
+			serviceAccount = &corev1.ServiceAccount{
 				ObjectMeta: metav1.ObjectMeta{
-					Name:      tokenSecretName,
+					Name:      serviceAccountName,
 					Namespace: controllers.DefaultManagedNamespace,
 				},
-				Data: map[string][]byte{
-					"token":  []byte("dGVzdA=="),
-					"ca.crt": []byte("dGVzdA=="),
+			}
+			Expect(cl.Create(ctx, serviceAccount)).To(Succeed())
+
+			caConfigMap = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      caConfigMapName,
+					Namespace: controllers.DefaultManagedNamespace,
+				},
+				Data: map[string]string{
+					caBundleKey: "dGVzdA==",
 				},
 			}
+			Expect(cl.Create(ctx, caConfigMap)).To(Succeed())
 
-			Expect(cl.Create(ctx, tokenSecret)).To(Succeed())
+			kubeconfigSecret = &corev1.Secret{}
+			kubeconfigSecretKey = client.ObjectKey{
+				Name:      fmt.Sprintf("%s-kubeconfig", r.clusterName),
+				Namespace: controllers.DefaultManagedNamespace,
+			}
 		})
 
 		AfterEach(func() {
-			Expect(test.CleanupAndWait(ctx, cl, tokenSecret, kubeconfigSecret)).To(Succeed())
+			kubeconfigSecret.SetName(kubeconfigSecretKey.Name)
+			kubeconfigSecret.SetNamespace(kubeconfigSecretKey.Namespace)
+			Expect(test.CleanupAndWait(ctx, cl, serviceAccount, caConfigMap, kubeconfigSecret)).To(Succeed())
 		})
 
 		It("should create a kubeconfig secret when it doesn't exist", func() {
-			_, err := r.reconcileKubeconfig(ctx)
+			res, err := r.reconcileKubeconfig(ctx)
 			Expect(err).To(Succeed())
+			Expect(res.RequeueAfter).To(BeNumerically("~", DefaultTokenExpiration/2, time.Minute))
 
-			Expect(cl.Get(ctx, client.ObjectKey{
-				Name:      fmt.Sprintf("%s-kubeconfig", r.clusterName),
-				Namespace: controllers.DefaultManagedNamespace,
-			}, kubeconfigSecret)).To(Succeed())
+			Expect(cl.Get(ctx, kubeconfigSecretKey, kubeconfigSecret)).To(Succeed())
 			Expect(kubeconfigSecret.Data).To(HaveKey("value")) // kubeconfig content is tested separately
+			Expect(kubeconfigSecret.Annotations).To(HaveKeyWithValue(serviceAccountUIDAnnotation, string(serviceAccount.UID)))
+			Expect(kubeconfigSecret.Annotations).To(HaveKey(tokenExpirationAnnotation))
+			Expect(rec.Events).To(Receive(ContainSubstring(kubeconfigCreatedReason)))
+		})
+
+		It("should not rotate a token that is not due for rotation", func() {
+			_, err := r.reconcileKubeconfig(ctx)
+			Expect(err).To(Succeed())
+			Expect(cl.Get(ctx, kubeconfigSecretKey, kubeconfigSecret)).To(Succeed())
+			resourceVersion := kubeconfigSecret.ResourceVersion
+
+			res, err := r.reconcileKubeconfig(ctx)
+			Expect(err).To(Succeed())
+			Expect(res.RequeueAfter).To(BeNumerically(">", 0))
+
+			Expect(cl.Get(ctx, kubeconfigSecretKey, kubeconfigSecret)).To(Succeed())
+			Expect(kubeconfigSecret.ResourceVersion).To(Equal(resourceVersion))
 		})
 
-		It("should reconcile existing kubeconfig secret when it doesn't exist", func() {
+		It("should rotate the token when the CA bundle changes", func() {
 			_, err := r.reconcileKubeconfig(ctx)
 			Expect(err).To(Succeed())
+			Expect(cl.Get(ctx, kubeconfigSecretKey, kubeconfigSecret)).To(Succeed())
+			kubeconfig := kubeconfigSecret.Data["value"]
+
+			caConfigMap.Data[caBundleKey] = "bmV3LWNh"
+			Expect(cl.Update(ctx, caConfigMap)).To(Succeed())
+
 			_, err = r.reconcileKubeconfig(ctx)
 			Expect(err).To(Succeed())
 
-			Expect(cl.Get(ctx, client.ObjectKey{
-				Name:      fmt.Sprintf("%s-kubeconfig", r.clusterName),
-				Namespace: controllers.DefaultManagedNamespace,
-			}, kubeconfigSecret)).To(Succeed())
-			Expect(kubeconfigSecret.Data).To(HaveKey("value")) // kubeconfig content is tested separately
+			Expect(cl.Get(ctx, kubeconfigSecretKey, kubeconfigSecret)).To(Succeed())
+			Expect(kubeconfigSecret.Data["value"]).NotTo(Equal(kubeconfig))
+			Expect(kubeconfigSecret.Annotations).To(HaveKeyWithValue(caHashAnnotation, hashCA([]byte("bmV3LWNh"))))
+			Expect(rec.Events).To(Receive(ContainSubstring(kubeconfigCreatedReason)))
+			Expect(rec.Events).To(Receive(And(ContainSubstring(tokenRotatedReason), ContainSubstring("CA bundle changed"))))
+		})
+
+		It("should rotate the token when the service account is recreated", func() {
+			_, err := r.reconcileKubeconfig(ctx)
+			Expect(err).To(Succeed())
+
+			Expect(test.CleanupAndWait(ctx, cl, serviceAccount)).To(Succeed())
+			serviceAccount = &corev1.ServiceAccount{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      serviceAccountName,
+					Namespace: controllers.DefaultManagedNamespace,
+				},
+			}
+			Expect(cl.Create(ctx, serviceAccount)).To(Succeed())
+
+			_, err = r.reconcileKubeconfig(ctx)
+			Expect(err).To(Succeed())
+
+			Expect(cl.Get(ctx, kubeconfigSecretKey, kubeconfigSecret)).To(Succeed())
+			Expect(kubeconfigSecret.Annotations).To(HaveKeyWithValue(serviceAccountUIDAnnotation, string(serviceAccount.UID)))
+		})
+
+		It("should embed the CA bundle of a custom ConfigMap and request custom audiences", func() {
+			customCA := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "custom-ca",
+					Namespace: controllers.DefaultManagedNamespace,
+				},
+				Data: map[string]string{
+					caBundleKey: "Y3VzdG9tLWNh",
+				},
+			}
+			Expect(cl.Create(ctx, customCA)).To(Succeed())
+			defer func() {
+				Expect(test.CleanupAndWait(ctx, cl, customCA)).To(Succeed())
+			}()
+
+			r.CAConfigMap = client.ObjectKeyFromObject(customCA)
+			r.TokenAudiences = []string{"capi-webhook", "api"}
+
+			_, err := r.reconcileKubeconfig(ctx)
+			Expect(err).To(Succeed())
+
+			Expect(cl.Get(ctx, kubeconfigSecretKey, kubeconfigSecret)).To(Succeed())
+			kubeconfig, err := clientcmd.Load(kubeconfigSecret.Data["value"])
+			Expect(err).NotTo(HaveOccurred())
+			Expect(kubeconfig.Clusters[r.clusterName].CertificateAuthorityData).To(Equal([]byte("Y3VzdG9tLWNh")))
+			Expect(kubeconfigSecret.Annotations).To(HaveKeyWithValue(tokenAudiencesAnnotation, "capi-webhook,api"))
+		})
+
+		It("should rotate the token when the audiences change", func() {
+			_, err := r.reconcileKubeconfig(ctx)
+			Expect(err).To(Succeed())
+
+			r.TokenAudiences = []string{"capi-webhook"}
+			_, err = r.reconcileKubeconfig(ctx)
+			Expect(err).To(Succeed())
+
+			Expect(cl.Get(ctx, kubeconfigSecretKey, kubeconfigSecret)).To(Succeed())
+			Expect(kubeconfigSecret.Annotations).To(HaveKeyWithValue(tokenAudiencesAnnotation, "capi-webhook"))
 		})
 
-		It("requeue when token secret doesn't exist", func() {
-			Expect(cl.Delete(ctx, tokenSecret)).To(Succeed())
-			Eventually(func() error {
-				return cl.Get(ctx, client.ObjectKeyFromObject(tokenSecret), tokenSecret)
-			}, timeout).Should(Not(Succeed()))
+		It("requeue when service account doesn't exist", func() {
+			Expect(test.CleanupAndWait(ctx, cl, serviceAccount)).To(Succeed())
 
 			res, err := r.reconcileKubeconfig(ctx)
 			Expect(err).To(Succeed())
 			Expect(res.RequeueAfter).To(Equal(1 * time.Minute))
 		})
 
-		It("should delete token secret if its old and requeue", func() {
-			// Use fake client because it's not possible to update creation timestamp in envtest
-			fakeClient := fake.NewClientBuilder().WithScheme(testEnv.Scheme).WithRuntimeObjects(tokenSecret).Build()
-			r.Client = fakeClient
-			tokenSecret.SetCreationTimestamp(metav1.Time{Time: time.Now().Add(-1 * time.Hour)})
-			Expect(fakeClient.Update(ctx, tokenSecret)).To(Succeed())
+		It("requeue when CA ConfigMap doesn't exist", func() {
+			Expect(test.CleanupAndWait(ctx, cl, caConfigMap)).To(Succeed())
+
 			res, err := r.reconcileKubeconfig(ctx)
 			Expect(err).To(Succeed())
-
 			Expect(res.RequeueAfter).To(Equal(1 * time.Minute))
-			Eventually(func() error {
-				return fakeClient.Get(ctx, client.ObjectKeyFromObject(tokenSecret), tokenSecret)
-			}, timeout).Should(Not(Succeed()))
 		})
 	})
 })
+
+var _ = Describe("Token requeue computation", func() {
+	issuedAt := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	DescribeTable("should rotate after the configured fraction of the token lifetime",
+		func(now, expiresAt time.Time, fraction float64, expected time.Duration) {
+			Expect(tokenRequeueAfter(now, issuedAt, expiresAt, time.Hour, fraction)).To(Equal(expected))
+		},
+		Entry("half of the lifetime right after issuing", issuedAt, issuedAt.Add(time.Hour), 0.5, 30*time.Minute),
+		Entry("custom fraction", issuedAt, issuedAt.Add(time.Hour), 0.8, 48*time.Minute),
+		Entry("partially elapsed lifetime", issuedAt.Add(20*time.Minute), issuedAt.Add(time.Hour), 0.5, 10*time.Minute),
+		Entry("overdue rotation", issuedAt.Add(40*time.Minute), issuedAt.Add(time.Hour), 0.5, -10*time.Minute),
+		Entry("lifetime shortened by the API server", issuedAt, issuedAt.Add(20*time.Minute), 0.5, 10*time.Minute),
+		Entry("expiration skewed ahead of the requested lifetime", issuedAt, issuedAt.Add(3*time.Hour), 0.5, 30*time.Minute),
+		Entry("expiration skewed before the issue time", issuedAt, issuedAt.Add(-time.Hour), 0.5, 30*time.Minute),
+		Entry("expiration equal to the issue time", issuedAt, issuedAt, 0.5, 30*time.Minute),
+	)
+})
+
+var _ = Describe("Token rotation fraction", func() {
+	DescribeTable("should fall back to the default for a fraction outside of (0, 1)",
+		func(fraction, expected float64) {
+			r := &KubeconfigReconciler{TokenRotationFraction: fraction}
+			Expect(r.tokenRotationFraction()).To(Equal(expected))
+		},
+		Entry("unset", 0.0, DefaultTokenRotationFraction),
+		Entry("negative", -0.5, DefaultTokenRotationFraction),
+		Entry("custom", 0.8, 0.8),
+		Entry("whole lifetime", 1.0, DefaultTokenRotationFraction),
+		Entry("beyond the lifetime", 1.5, DefaultTokenRotationFraction),
+	)
+})
+
+var _ = Describe("Parse ConfigMap reference", func() {
+	It("should parse a namespace/name reference", func() {
+		key, err := ParseConfigMapReference("openshift-config-managed/kube-root-ca.crt")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(key).To(Equal(client.ObjectKey{Namespace: "openshift-config-managed", Name: "kube-root-ca.crt"}))
+	})
+
+	DescribeTable("should reject invalid references",
+		func(ref string) {
+			_, err := ParseConfigMapReference(ref)
+			Expect(err).To(HaveOccurred())
+		},
+		Entry("name only", "kube-root-ca.crt"),
+		Entry("empty namespace", "/kube-root-ca.crt"),
+		Entry("empty name", "openshift-config/"),
+		Entry("too many segments", "a/b/c"),
+	)
+})
@@ -0,0 +1,52 @@
+package kubeconfig
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var tokenExpiryDesc = prometheus.NewDesc(
+	"cluster_capi_operator_kubeconfig_token_expiry_seconds",
+	"Seconds until the token of the kubeconfig used by the CAPI controllers expires. Negative once it expired.",
+	nil, nil,
+)
+
+// tokenExpiryCollector reports the time until the current kubeconfig token expires. Nothing is reported until a token is known.
+type tokenExpiryCollector struct {
+	mu        sync.Mutex
+	expiresAt time.Time
+	now       func() time.Time
+}
+
+var tokenExpiry = &tokenExpiryCollector{now: time.Now}
+
+func init() {
+	metrics.Registry.MustRegister(tokenExpiry)
+}
+
+func (c *tokenExpiryCollector) set(expiresAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.expiresAt = expiresAt
+}
+
+// Describe implements prometheus.Collector.
+func (c *tokenExpiryCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- tokenExpiryDesc
+}
+
+// Collect implements prometheus.Collector.
+func (c *tokenExpiryCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.expiresAt.IsZero() {
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(tokenExpiryDesc, prometheus.GaugeValue, c.expiresAt.Sub(c.now()).Seconds())
+}
@@ -0,0 +1,42 @@
+package kubeconfig
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+var _ = Describe("Token expiry metric", func() {
+	now := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	collect := func(c *tokenExpiryCollector) []prometheus.Metric {
+		ch := make(chan prometheus.Metric, 1)
+		c.Collect(ch)
+		close(ch)
+
+		metrics := []prometheus.Metric{}
+		for m := range ch {
+			metrics = append(metrics, m)
+		}
+		return metrics
+	}
+
+	It("should not report anything until a token is known", func() {
+		Expect(collect(&tokenExpiryCollector{now: func() time.Time { return now }})).To(BeEmpty())
+	})
+
+	It("should report the seconds until the token expires", func() {
+		c := &tokenExpiryCollector{now: func() time.Time { return now }}
+		c.set(now.Add(30 * time.Minute))
+
+		metrics := collect(c)
+		Expect(metrics).To(HaveLen(1))
+
+		metric := &dto.Metric{}
+		Expect(metrics[0].Write(metric)).To(Succeed())
+		Expect(metric.GetGauge().GetValue()).To(Equal(1800.0))
+	})
+})
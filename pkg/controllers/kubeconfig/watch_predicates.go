@@ -11,29 +11,48 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/openshift/cluster-capi-operator/pkg/controllers"
+	"github.com/openshift/cluster-capi-operator/pkg/util"
 )
 
-func toTokenSecret(client.Object) []reconcile.Request {
+func toServiceAccount(client.Object) []reconcile.Request {
 	return []reconcile.Request{{
-		NamespacedName: client.ObjectKey{Name: tokenSecretName, Namespace: controllers.DefaultManagedNamespace},
+		NamespacedName: client.ObjectKey{Name: serviceAccountName, Namespace: controllers.DefaultManagedNamespace},
 	}}
 }
 
-func tokenSecretPredicate() predicate.Funcs {
-	isOwnedTokenSecret := func(obj runtime.Object) bool {
-		secret, ok := obj.(*corev1.Secret)
+func serviceAccountPredicate() predicate.Funcs {
+	isOperatorServiceAccount := func(obj runtime.Object) bool {
+		serviceAccount, ok := obj.(*corev1.ServiceAccount)
 		if !ok {
-			panic("expected to get an of object of type corev1.Secret")
+			panic("expected to get an of object of type corev1.ServiceAccount")
 		}
 
-		return secret.GetNamespace() == controllers.DefaultManagedNamespace && secret.GetName() == tokenSecretName
+		return serviceAccount.GetNamespace() == controllers.DefaultManagedNamespace && serviceAccount.GetName() == serviceAccountName
 	}
 
 	return predicate.Funcs{
-		CreateFunc:  func(e event.CreateEvent) bool { return isOwnedTokenSecret(e.Object) },
-		UpdateFunc:  func(e event.UpdateEvent) bool { return isOwnedTokenSecret(e.ObjectNew) },
-		DeleteFunc:  func(e event.DeleteEvent) bool { return isOwnedTokenSecret(e.Object) },
-		GenericFunc: func(e event.GenericEvent) bool { return isOwnedTokenSecret(e.Object) },
+		CreateFunc:  func(e event.CreateEvent) bool { return isOperatorServiceAccount(e.Object) },
+		UpdateFunc:  func(e event.UpdateEvent) bool { return isOperatorServiceAccount(e.ObjectNew) },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return isOperatorServiceAccount(e.Object) },
+		GenericFunc: func(e event.GenericEvent) bool { return isOperatorServiceAccount(e.Object) },
+	}
+}
+
+func caConfigMapPredicate(key client.ObjectKey) predicate.Funcs {
+	isCAConfigMap := func(obj runtime.Object) bool {
+		configMap, ok := obj.(*corev1.ConfigMap)
+		if !ok {
+			panic("expected to get an of object of type corev1.ConfigMap")
+		}
+
+		return configMap.GetNamespace() == key.Namespace && configMap.GetName() == key.Name
+	}
+
+	return predicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return isCAConfigMap(e.Object) },
+		UpdateFunc:  func(e event.UpdateEvent) bool { return isCAConfigMap(e.ObjectNew) },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return isCAConfigMap(e.Object) },
+		GenericFunc: func(e event.GenericEvent) bool { return isCAConfigMap(e.Object) },
 	}
 }
 
@@ -54,3 +73,9 @@ func kubeconfigSecretPredicate() predicate.Funcs {
 		GenericFunc: func(e event.GenericEvent) bool { return isKubeconfigSecret(e.Object) },
 	}
 }
+
+func providerOverridesConfigMapPredicate(namespace string) predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return util.IsProviderOverridesConfigMap(obj, namespace)
+	})
+}
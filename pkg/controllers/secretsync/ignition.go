@@ -0,0 +1,42 @@
+package secretsync
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// supportedIgnitionVersions lists the ignition config spec versions recognised in user data.
+var supportedIgnitionVersions = map[string]bool{
+	"2.2.0": true,
+	"2.3.0": true,
+	"3.0.0": true,
+	"3.1.0": true,
+	"3.2.0": true,
+	"3.3.0": true,
+	"3.4.0": true,
+}
+
+type ignitionConfig struct {
+	Ignition struct {
+		Version string `json:"version"`
+	} `json:"ignition"`
+}
+
+// validateIgnitionConfig checks that the user data is an ignition config of a recognised spec version.
+func validateIgnitionConfig(userData []byte) error {
+	config := &ignitionConfig{}
+	if err := json.Unmarshal(userData, config); err != nil {
+		return fmt.Errorf("unable to parse ignition config: %v", err)
+	}
+
+	if config.Ignition.Version == "" {
+		return errors.New("ignition config has no version")
+	}
+
+	if !supportedIgnitionVersions[config.Ignition.Version] {
+		return fmt.Errorf("unsupported ignition config version %q", config.Ignition.Version)
+	}
+
+	return nil
+}
@@ -7,11 +7,13 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	configv1 "github.com/openshift/api/config/v1"
@@ -29,6 +31,18 @@ const (
 
 	mapiUserDataKey = "userData"
 	capiUserDataKey = "value"
+
+	// capiFormatKey holds the format of the bootstrap data in CAPI bootstrap secrets.
+	capiFormatKey  = "format"
+	ignitionFormat = "ignition"
+
+	// managedByLabel is set on every secret synced by the controller, so secrets that are no longer referenced
+	// can be found and cleaned up.
+	managedByLabel    = "cluster-capi-operator.openshift.io/managed-by"
+	secretSyncManager = "secret-sync-controller"
+
+	invalidUserDataSecretReason = "InvalidUserDataSecret"
+	invalidIgnitionConfigReason = "InvalidIgnitionConfig"
 )
 
 type UserDataSecretController struct {
@@ -39,75 +53,148 @@ type UserDataSecretController struct {
 func (r *UserDataSecretController) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := ctrl.LoggerFrom(ctx).WithName("SecretSyncController")
 
-	log.Info("reconciling worker user data secret")
+	log.Info("reconciling user data secrets")
 
-	defaultSourceSecretObjectKey := client.ObjectKey{
-		Name: managedUserDataSecretName, Namespace: SecretSourceNamespace,
-	}
-	sourceSecret := &corev1.Secret{}
-	if err := r.Get(ctx, defaultSourceSecretObjectKey, sourceSecret); err != nil {
-		log.Error(err, "unable to get source secret for sync")
+	names, err := referencedUserDataSecrets(ctx, r.Client)
+	if err != nil {
+		log.Error(err, "unable to get user data secrets referenced by Machine API")
 		if err := r.setDegradedCondition(ctx); err != nil {
 			return ctrl.Result{}, fmt.Errorf("failed to set conditions for secret sync controller: %v", err)
 		}
 		return ctrl.Result{}, err
 	}
 
+	for _, name := range names {
+		if err := r.syncUserDataSecret(ctx, name); err != nil {
+			log.Error(err, "unable to sync user data secret", "name", name)
+			if err := r.setDegradedCondition(ctx); err != nil {
+				return ctrl.Result{}, fmt.Errorf("failed to set conditions for user data secret controller: %v", err)
+			}
+			return ctrl.Result{}, err
+		}
+	}
+
+	if err := r.pruneUserDataSecrets(ctx, names); err != nil {
+		log.Error(err, "unable to clean up user data secrets that are no longer referenced")
+		if err := r.setDegradedCondition(ctx); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to set conditions for user data secret controller: %v", err)
+		}
+		return ctrl.Result{}, err
+	}
+
+	if err := r.setAvailableCondition(ctx); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to set conditions for user data secret controller: %v", err)
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// syncUserDataSecret mirrors the named user data secret from the source namespace into the managed namespace.
+// Referenced secrets that do not exist yet, and secrets that do not hold user data are skipped.
+func (r *UserDataSecretController) syncUserDataSecret(ctx context.Context, name string) error {
+	log := ctrl.LoggerFrom(ctx).WithValues("name", name)
+
+	sourceSecret := &corev1.Secret{}
+	if err := r.Get(ctx, client.ObjectKey{Name: name, Namespace: SecretSourceNamespace}, sourceSecret); err != nil {
+		// The worker user data secret is created by the installer and is expected to always exist.
+		if errors.IsNotFound(err) && name != managedUserDataSecretName {
+			log.Info("referenced user data secret does not exist, skipping sync")
+			return nil
+		}
+		return fmt.Errorf("unable to get source secret for sync: %v", err)
+	}
+
+	if sourceSecret.Data[mapiUserDataKey] == nil {
+		log.Info("referenced secret does not contain user data, skipping sync")
+		r.Recorder.Eventf(sourceSecret, corev1.EventTypeWarning, invalidUserDataSecretReason,
+			"Secret is referenced as user data by Machine API but has no %q key, it is not synced", mapiUserDataKey)
+		return nil
+	}
+
 	targetSecret := &corev1.Secret{}
 	targetSecretKey := client.ObjectKey{
 		Namespace: r.ManagedNamespace,
-		Name:      managedUserDataSecretName,
+		Name:      name,
 	}
 
 	// If the secret does not exist, it will be created later, so we can ignore a Not Found error
 	if err := r.Get(ctx, targetSecretKey, targetSecret); err != nil && !errors.IsNotFound(err) {
-		log.Error(err, "unable to get target secret for sync")
-		if err := r.setDegradedCondition(ctx); err != nil {
-			return ctrl.Result{}, fmt.Errorf("failed to set conditions for secret controller: %v", err)
-		}
-		return ctrl.Result{}, err
+		return fmt.Errorf("unable to get target secret for sync: %v", err)
 	}
 
-	if r.areSecretsEqual(sourceSecret, targetSecret) {
+	if r.areSecretsEqual(sourceSecret, targetSecret) && targetSecret.Labels[managedByLabel] == secretSyncManager {
 		log.Info("user data in source and target secrets is the same, no sync needed")
-		if err := r.setAvailableCondition(ctx); err != nil {
-			return ctrl.Result{}, fmt.Errorf("failed to set conditions for user data secret controller: %v", err)
-		}
-		return ctrl.Result{}, nil
+		return nil
 	}
 
-	if err := r.syncSecretData(ctx, sourceSecret, targetSecret); err != nil {
-		log.Error(err, "unable to sync user data secret")
-		if err := r.setDegradedCondition(ctx); err != nil {
-			return ctrl.Result{}, fmt.Errorf("failed to set conditions for user data secret controller: %v", err)
-		}
-		return ctrl.Result{}, err
+	return r.syncSecretData(ctx, sourceSecret, targetSecret)
+}
+
+// pruneUserDataSecrets deletes the secrets synced by the controller whose source secret is no longer referenced.
+func (r *UserDataSecretController) pruneUserDataSecrets(ctx context.Context, referenced []string) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	referencedNames := map[string]bool{}
+	for _, name := range referenced {
+		referencedNames[name] = true
 	}
 
-	if err := r.setAvailableCondition(ctx); err != nil {
-		return ctrl.Result{}, fmt.Errorf("failed to set conditions for user data secret controller: %v", err)
+	secrets := &corev1.SecretList{}
+	if err := r.List(ctx, secrets, client.InNamespace(r.ManagedNamespace), client.MatchingLabels{managedByLabel: secretSyncManager}); err != nil {
+		return fmt.Errorf("unable to list synced secrets: %v", err)
 	}
 
-	return ctrl.Result{}, nil
+	for i := range secrets.Items {
+		secret := &secrets.Items[i]
+		if referencedNames[secret.Name] || !secret.GetDeletionTimestamp().IsZero() {
+			continue
+		}
+
+		log.Info("deleting synced user data secret that is no longer referenced", "name", secret.Name)
+		if err := r.Delete(ctx, secret); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("unable to delete secret %s: %v", secret.Name, err)
+		}
+	}
+
+	return nil
 }
 
 func (r *UserDataSecretController) areSecretsEqual(source *corev1.Secret, target *corev1.Secret) bool {
 	return source.Immutable == target.Immutable &&
-		reflect.DeepEqual(source.Data[mapiUserDataKey], target.Data[capiUserDataKey]) && reflect.DeepEqual(source.StringData, target.StringData) &&
+		reflect.DeepEqual(bootstrapSecretData(source), target.Data) && reflect.DeepEqual(source.StringData, target.StringData) &&
 		source.Type == target.Type
 }
 
+// bootstrapSecretData converts the data of a Machine API user data secret into the CAPI bootstrap secret format.
+// The ignition payload is set under the value key along with its format, and the original keys are preserved for
+// backwards compatibility.
+func bootstrapSecretData(source *corev1.Secret) map[string][]byte {
+	data := make(map[string][]byte, len(source.Data)+2)
+	for key, value := range source.Data {
+		data[key] = value
+	}
+
+	data[capiUserDataKey] = source.Data[mapiUserDataKey]
+	data[capiFormatKey] = []byte(ignitionFormat)
+
+	return data
+}
+
 func (r *UserDataSecretController) syncSecretData(ctx context.Context, source *corev1.Secret, target *corev1.Secret) error {
 	userData := source.Data[mapiUserDataKey]
 	if userData == nil {
 		return fmt.Errorf("source secret does not have user data")
 	}
 
-	target.SetName(managedUserDataSecretName)
+	target.SetName(source.Name)
 	target.SetNamespace(r.ManagedNamespace)
-	target.Data = map[string][]byte{
-		"value": userData,
+	target.SetLabels(map[string]string{managedByLabel: secretSyncManager})
+	if err := validateIgnitionConfig(userData); err != nil {
+		ctrl.LoggerFrom(ctx).Info("user data is not a valid ignition config, syncing it anyway", "name", source.Name, "reason", err.Error())
+		r.Recorder.Eventf(source, corev1.EventTypeWarning, invalidIgnitionConfigReason, "User data is not a recognised ignition config: %v", err)
 	}
+
+	target.Data = bootstrapSecretData(source)
 	target.StringData = source.StringData
 	target.Immutable = source.Immutable
 	target.Type = source.Type
@@ -125,15 +212,23 @@ func (r *UserDataSecretController) syncSecretData(ctx context.Context, source *c
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *UserDataSecretController) SetupWithManager(mgr ctrl.Manager) error {
+	machineSet := &unstructured.Unstructured{}
+	machineSet.SetGroupVersionKind(machineSetGVK)
+
 	build := ctrl.NewControllerManagedBy(mgr).
 		For(
 			&corev1.Secret{},
-			builder.WithPredicates(userDataSecretPredicate(r.ManagedNamespace)),
+			builder.WithPredicates(syncedSecretPredicate(r.ManagedNamespace)),
 		).
 		Watches(
 			&source.Kind{Type: &corev1.Secret{}},
 			handler.EnqueueRequestsFromMapFunc(toUserDataSecret),
-			builder.WithPredicates(userDataSecretPredicate(SecretSourceNamespace)),
+			builder.WithPredicates(namespacePredicate(SecretSourceNamespace)),
+		).
+		Watches(
+			&source.Kind{Type: machineSet},
+			handler.EnqueueRequestsFromMapFunc(toUserDataSecret),
+			builder.WithPredicates(namespacePredicate(SecretSourceNamespace), predicate.GenerationChangedPredicate{}),
 		)
 
 	return build.Complete(r)
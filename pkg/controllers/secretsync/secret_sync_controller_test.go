@@ -9,6 +9,7 @@ import (
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/pointer"
@@ -22,11 +23,21 @@ import (
 )
 
 const (
-	defaultSecretValue = "bar"
+	defaultSecretValue = `{"ignition":{"version":"3.2.0"}}`
 
 	timeout = time.Second * 10
 )
 
+func makeMachineSet(name, userDataSecretName string) *unstructured.Unstructured {
+	machineSet := &unstructured.Unstructured{}
+	machineSet.SetGroupVersionKind(machineSetGVK)
+	machineSet.SetName(name)
+	machineSet.SetNamespace(SecretSourceNamespace)
+	Expect(unstructured.SetNestedField(machineSet.Object, userDataSecretName,
+		"spec", "template", "spec", "providerSpec", "value", "userDataSecret", "name")).To(Succeed())
+	return machineSet
+}
+
 func makeUserDataSecret() *corev1.Secret {
 	return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
 		Name:      managedUserDataSecretName,
@@ -44,6 +55,7 @@ var _ = Describe("areSecretsEqual reconciler method", func() {
 		sourceUserDataSecret = makeUserDataSecret()
 		targetUserDataSecret = makeUserDataSecret()
 		targetUserDataSecret.Data[capiUserDataKey] = sourceUserDataSecret.Data[mapiUserDataKey]
+		targetUserDataSecret.Data[capiFormatKey] = []byte(ignitionFormat)
 	})
 
 	It("should return 'true' if Secrets content are equal", func() {
@@ -59,6 +71,47 @@ var _ = Describe("areSecretsEqual reconciler method", func() {
 	})
 })
 
+var _ = Describe("bootstrapSecretData", func() {
+	It("should set the payload under the value key with the ignition format and preserve the original keys", func() {
+		source := makeUserDataSecret()
+		source.Data["disableTemplating"] = []byte("true")
+
+		Expect(bootstrapSecretData(source)).To(Equal(map[string][]byte{
+			mapiUserDataKey:     []byte(defaultSecretValue),
+			capiUserDataKey:     []byte(defaultSecretValue),
+			capiFormatKey:       []byte(ignitionFormat),
+			"disableTemplating": []byte("true"),
+		}))
+	})
+
+	It("should not modify the source secret", func() {
+		source := makeUserDataSecret()
+		bootstrapSecretData(source)
+		Expect(source.Data).To(Equal(map[string][]byte{mapiUserDataKey: []byte(defaultSecretValue)}))
+	})
+})
+
+var _ = Describe("validateIgnitionConfig", func() {
+	DescribeTable("should accept recognised ignition configs",
+		func(userData string) {
+			Expect(validateIgnitionConfig([]byte(userData))).To(Succeed())
+		},
+		Entry("3.1 pointer config", `{"ignition":{"config":{"merge":[{"source":"https://api-int.example.com:22623/config/worker"}]},"version":"3.1.0"}}`),
+		Entry("3.2 pointer config with CA", `{"ignition":{"config":{"merge":[{"source":"https://api-int.example.com:22623/config/worker"}]},"security":{"tls":{"certificateAuthorities":[{"source":"data:text/plain;charset=utf-8;base64,dGVzdA=="}]}},"version":"3.2.0"}}`),
+		Entry("3.4 config", `{"ignition":{"version":"3.4.0"},"storage":{"files":[]}}`),
+	)
+
+	DescribeTable("should reject invalid ignition configs",
+		func(userData string) {
+			Expect(validateIgnitionConfig([]byte(userData))).NotTo(Succeed())
+		},
+		Entry("malformed payload", `{"ignition":{"version":"3.2.0"`),
+		Entry("cloud-config payload", "#cloud-config\nruncmd: []\n"),
+		Entry("missing version", `{"ignition":{}}`),
+		Entry("unknown version", `{"ignition":{"version":"4.0.0"}}`),
+	)
+})
+
 var _ = Describe("User Data Secret controller", func() {
 	var rec *record.FakeRecorder
 
@@ -73,6 +126,8 @@ var _ = Describe("User Data Secret controller", func() {
 	syncedSecretKey := client.ObjectKey{Namespace: controllers.DefaultManagedNamespace, Name: managedUserDataSecretName}
 
 	BeforeEach(func() {
+		rec = record.NewFakeRecorder(100)
+
 		By("Setting up a new manager")
 		mgr, err := manager.New(cfg, manager.Options{MetricsBindAddress: "0"})
 		Expect(err).NotTo(HaveOccurred())
@@ -117,6 +172,13 @@ var _ = Describe("User Data Secret controller", func() {
 		Expect(test.CleanupAndWait(ctx, cl, co))
 
 		By("Cleanup resources")
+		machineSets := &unstructured.UnstructuredList{}
+		machineSets.SetGroupVersionKind(machineSetListGVK)
+		Expect(cl.List(ctx, machineSets)).To(Succeed())
+		for _, machineSet := range machineSets.Items {
+			Expect(test.CleanupAndWait(ctx, cl, machineSet.DeepCopy())).To(Succeed())
+		}
+
 		allSecrets := &corev1.SecretList{}
 		Expect(cl.List(ctx, allSecrets)).To(Succeed())
 		for _, cm := range allSecrets.Items {
@@ -140,6 +202,19 @@ var _ = Describe("User Data Secret controller", func() {
 			}
 			return bytes.Equal(syncedUserDataSecret.Data[capiUserDataKey], []byte(defaultSecretValue)), nil
 		}, timeout).Should(BeTrue())
+
+		syncedUserDataSecret := &corev1.Secret{}
+		Expect(cl.Get(ctx, syncedSecretKey, syncedUserDataSecret)).To(Succeed())
+		Expect(syncedUserDataSecret.Data).To(HaveKeyWithValue(capiFormatKey, []byte(ignitionFormat)))
+		Expect(syncedUserDataSecret.Data).To(HaveKeyWithValue(mapiUserDataKey, []byte(defaultSecretValue)))
+	})
+
+	It("should emit an event when the user data is not a valid ignition config", func() {
+		changedSourceSecret := sourceSecret.DeepCopy()
+		changedSourceSecret.Data = map[string][]byte{mapiUserDataKey: []byte(`{"ignition":`)}
+		Expect(cl.Update(ctx, changedSourceSecret)).To(Succeed())
+
+		Eventually(rec.Events, timeout).Should(Receive(ContainSubstring(invalidIgnitionConfigReason)))
 	})
 
 	It("secret should be synced up if managed user data secret changed", func() {
@@ -186,4 +261,65 @@ var _ = Describe("User Data Secret controller", func() {
 		Expect(cl.Get(ctx, syncedSecretKey, syncedUserDataSecret)).Should(Succeed())
 		Expect(initialSecretresourceVersion).Should(BeEquivalentTo(syncedUserDataSecret.ResourceVersion))
 	})
+
+	Context("with user data secrets referenced by MachineSets", func() {
+		infraSecretKey := client.ObjectKey{Namespace: controllers.DefaultManagedNamespace, Name: "infra-user-data"}
+
+		var infraSecret *corev1.Secret
+
+		BeforeEach(func() {
+			infraSecret = &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+				Name:      infraSecretKey.Name,
+				Namespace: SecretSourceNamespace,
+			}, Data: map[string][]byte{mapiUserDataKey: []byte("infra")}}
+			Expect(cl.Create(ctx, infraSecret)).To(Succeed())
+		})
+
+		It("should sync a secret once it is referenced by a MachineSet", func() {
+			Consistently(func() error {
+				return cl.Get(ctx, infraSecretKey, &corev1.Secret{})
+			}, time.Second).ShouldNot(Succeed())
+
+			Expect(cl.Create(ctx, makeMachineSet("infra", infraSecretKey.Name))).To(Succeed())
+
+			Eventually(func() (bool, error) {
+				syncedSecret := &corev1.Secret{}
+				if err := cl.Get(ctx, infraSecretKey, syncedSecret); err != nil {
+					return false, err
+				}
+				return bytes.Equal(syncedSecret.Data[capiUserDataKey], []byte("infra")), nil
+			}, timeout).Should(BeTrue())
+		})
+
+		It("should clean up a synced secret once it is no longer referenced", func() {
+			machineSet := makeMachineSet("infra", infraSecretKey.Name)
+			Expect(cl.Create(ctx, machineSet)).To(Succeed())
+
+			Eventually(func() error {
+				return cl.Get(ctx, infraSecretKey, &corev1.Secret{})
+			}, timeout).Should(Succeed())
+
+			Expect(test.CleanupAndWait(ctx, cl, machineSet)).To(Succeed())
+
+			Eventually(func() error {
+				return cl.Get(ctx, infraSecretKey, &corev1.Secret{})
+			}, timeout).ShouldNot(Succeed())
+
+			By("Keeping the worker user data secret")
+			Expect(cl.Get(ctx, syncedSecretKey, &corev1.Secret{})).To(Succeed())
+		})
+
+		It("should skip a referenced secret without user data", func() {
+			invalidSecret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+				Name:      "invalid-user-data",
+				Namespace: SecretSourceNamespace,
+			}, Data: map[string][]byte{"foo": []byte("bar")}}
+			Expect(cl.Create(ctx, invalidSecret)).To(Succeed())
+			Expect(cl.Create(ctx, makeMachineSet("invalid", invalidSecret.Name))).To(Succeed())
+
+			Eventually(rec.Events, timeout).Should(Receive(ContainSubstring(invalidUserDataSecretReason)))
+			Expect(cl.Get(ctx, client.ObjectKey{Namespace: controllers.DefaultManagedNamespace, Name: invalidSecret.Name}, &corev1.Secret{})).NotTo(Succeed())
+			Expect(cl.Get(ctx, syncedSecretKey, &corev1.Secret{})).To(Succeed())
+		})
+	})
 })
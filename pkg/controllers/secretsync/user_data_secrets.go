@@ -0,0 +1,64 @@
+package secretsync
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	machineSetGVK     = schema.GroupVersionKind{Group: "machine.openshift.io", Version: "v1beta1", Kind: "MachineSet"}
+	machineSetListGVK = schema.GroupVersionKind{Group: "machine.openshift.io", Version: "v1beta1", Kind: "MachineSetList"}
+	machineListGVK    = schema.GroupVersionKind{Group: "machine.openshift.io", Version: "v1beta1", Kind: "MachineList"}
+)
+
+// referencedUserDataSecrets returns the sorted names of the user data secrets referenced by the Machine API MachineSets
+// and Machines in the source namespace. The managed worker user data secret is always part of the result.
+func referencedUserDataSecrets(ctx context.Context, cl client.Reader) ([]string, error) {
+	names := map[string]bool{managedUserDataSecretName: true}
+
+	machineSets := &unstructured.UnstructuredList{}
+	machineSets.SetGroupVersionKind(machineSetListGVK)
+	if err := cl.List(ctx, machineSets, client.InNamespace(SecretSourceNamespace)); err != nil && !meta.IsNoMatchError(err) {
+		return nil, fmt.Errorf("unable to list Machine API MachineSets: %v", err)
+	}
+
+	for _, machineSet := range machineSets.Items {
+		name, _, err := unstructured.NestedString(machineSet.Object, "spec", "template", "spec", "providerSpec", "value", "userDataSecret", "name")
+		if err != nil {
+			return nil, fmt.Errorf("unable to read user data secret of MachineSet %s: %v", machineSet.GetName(), err)
+		}
+		if name != "" {
+			names[name] = true
+		}
+	}
+
+	machines := &unstructured.UnstructuredList{}
+	machines.SetGroupVersionKind(machineListGVK)
+	if err := cl.List(ctx, machines, client.InNamespace(SecretSourceNamespace)); err != nil && !meta.IsNoMatchError(err) {
+		return nil, fmt.Errorf("unable to list Machine API Machines: %v", err)
+	}
+
+	for _, machine := range machines.Items {
+		name, _, err := unstructured.NestedString(machine.Object, "spec", "providerSpec", "value", "userDataSecret", "name")
+		if err != nil {
+			return nil, fmt.Errorf("unable to read user data secret of Machine %s: %v", machine.GetName(), err)
+		}
+		if name != "" {
+			names[name] = true
+		}
+	}
+
+	result := make([]string, 0, len(names))
+	for name := range names {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+
+	return result, nil
+}
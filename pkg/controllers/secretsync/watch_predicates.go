@@ -15,16 +15,33 @@ func toUserDataSecret(client.Object) []reconcile.Request {
 	}}
 }
 
-func userDataSecretPredicate(targetNamespace string) predicate.Funcs {
-	isOwnedUserDataSecret := func(obj runtime.Object) bool {
+// syncedSecretPredicate matches the secrets synced by the controller in the target namespace.
+func syncedSecretPredicate(targetNamespace string) predicate.Funcs {
+	isSyncedSecret := func(obj runtime.Object) bool {
 		secret, ok := obj.(*corev1.Secret)
-		return ok && secret.GetNamespace() == targetNamespace && secret.GetName() == managedUserDataSecretName
+		return ok && secret.GetNamespace() == targetNamespace &&
+			(secret.GetName() == managedUserDataSecretName || secret.GetLabels()[managedByLabel] == secretSyncManager)
 	}
 
 	return predicate.Funcs{
-		CreateFunc:  func(e event.CreateEvent) bool { return isOwnedUserDataSecret(e.Object) },
-		UpdateFunc:  func(e event.UpdateEvent) bool { return isOwnedUserDataSecret(e.ObjectNew) },
-		DeleteFunc:  func(e event.DeleteEvent) bool { return isOwnedUserDataSecret(e.Object) },
-		GenericFunc: func(e event.GenericEvent) bool { return isOwnedUserDataSecret(e.Object) },
+		CreateFunc:  func(e event.CreateEvent) bool { return isSyncedSecret(e.Object) },
+		UpdateFunc:  func(e event.UpdateEvent) bool { return isSyncedSecret(e.ObjectNew) },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return isSyncedSecret(e.Object) },
+		GenericFunc: func(e event.GenericEvent) bool { return isSyncedSecret(e.Object) },
+	}
+}
+
+// namespacePredicate matches objects in the given namespace. All user data secret candidates and MachineSets
+// of the source namespace are matched, as the set of referenced secrets is only known while reconciling.
+func namespacePredicate(namespace string) predicate.Funcs {
+	inNamespace := func(obj client.Object) bool {
+		return obj.GetNamespace() == namespace
+	}
+
+	return predicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return inNamespace(e.Object) },
+		UpdateFunc:  func(e event.UpdateEvent) bool { return inNamespace(e.ObjectNew) },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return inNamespace(e.Object) },
+		GenericFunc: func(e event.GenericEvent) bool { return inNamespace(e.Object) },
 	}
 }
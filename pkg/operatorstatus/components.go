@@ -0,0 +1,251 @@
+package operatorstatus
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/cluster-capi-operator/pkg/controllers"
+	"github.com/openshift/library-go/pkg/config/clusteroperator/v1helpers"
+)
+
+const (
+	ReasonComponentsNotAvailable = "ComponentsNotAvailable"
+	ReasonComponentsDegraded     = "ComponentsDegraded"
+
+	// controllerAvailableSuffix and controllerDegradedSuffix form the condition types a controller reports
+	// its own state with, e.g. InfraClusterControllerDegraded.
+	controllerAvailableSuffix = "Available"
+	controllerDegradedSuffix  = "Degraded"
+)
+
+// ComponentStatus is the observed state of a single component managed by the operator.
+type ComponentStatus struct {
+	// Name identifies the component, e.g. Deployment/capi-controller-manager.
+	Name string
+	// Available is true when the component is applied and serving.
+	Available bool
+	// Degraded is true when the component failed and is not expected to recover on its own.
+	Degraded bool
+	// Message explains why the component is not available or degraded.
+	Message string
+}
+
+// failedContainerReasons are the waiting reasons of containers that are not expected to start without intervention.
+var failedContainerReasons = map[string]bool{
+	"CrashLoopBackOff":           true,
+	"ImagePullBackOff":           true,
+	"ErrImageNeverPull":          true,
+	"InvalidImageName":           true,
+	"CreateContainerConfigError": true,
+	"CreateContainerError":       true,
+}
+
+// DeploymentComponentStatus returns the component status of the given Deployment. The Deployment is degraded
+// when one of the given pods has a container that fails to start, e.g. because its image cannot be pulled.
+func DeploymentComponentStatus(deployment *appsv1.Deployment, pods ...corev1.Pod) ComponentStatus {
+	status := ComponentStatus{
+		Name: fmt.Sprintf("Deployment/%s", deployment.Name),
+	}
+
+	for _, cond := range deployment.Status.Conditions {
+		switch {
+		case cond.Type == appsv1.DeploymentReplicaFailure && cond.Status == corev1.ConditionTrue:
+			status.Degraded = true
+			status.Message = cond.Message
+		case cond.Type == appsv1.DeploymentProgressing && cond.Status == corev1.ConditionFalse:
+			status.Degraded = true
+			status.Message = cond.Message
+		case cond.Type == appsv1.DeploymentAvailable:
+			status.Available = cond.Status == corev1.ConditionTrue
+			if !status.Available && status.Message == "" {
+				status.Message = cond.Message
+			}
+		}
+	}
+
+	if message := failedContainerMessage(pods); message != "" {
+		status.Degraded = true
+		status.Message = message
+	}
+
+	if !status.Available && status.Message == "" {
+		status.Message = "waiting for deployment to become available"
+	}
+
+	return status
+}
+
+// failedContainerMessage describes the first container of the pods that is waiting for a failure reason.
+// It returns an empty string when no container failed.
+func failedContainerMessage(pods []corev1.Pod) string {
+	for _, pod := range pods {
+		statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+		for _, container := range statuses {
+			waiting := container.State.Waiting
+			if waiting == nil || !failedContainerReasons[waiting.Reason] {
+				continue
+			}
+
+			message := fmt.Sprintf("container %s of pod %s is in %s", container.Name, pod.Name, waiting.Reason)
+			if waiting.Message != "" {
+				message = fmt.Sprintf("%s: %s", message, waiting.Message)
+			}
+			return message
+		}
+	}
+	return ""
+}
+
+// SetStatusFromComponents sets the Available condition to True only when every component is available
+// and none is degraded. Otherwise, the Available and Degraded conditions are set accordingly, with
+// messages naming each failing component. Controllers reporting their own Degraded condition are
+// components as well, so the ClusterOperator is only healthy when they are.
+func (r *ClusterOperatorStatusClient) SetStatusFromComponents(ctx context.Context, components []ComponentStatus) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	co, err := r.GetOrCreateClusterOperator(ctx)
+	if err != nil {
+		log.Error(err, "unable to set cluster operator status from components")
+		return err
+	}
+
+	components = append(components, controllerComponents(co.Status.Conditions)...)
+
+	conds := componentConditions(components, r.ReleaseVersion)
+	if conds == nil {
+		return r.SetStatusAvailable(ctx)
+	}
+
+	// Update cluster conditions only if they have been changed
+	for _, cond := range conds {
+		if !isStatusConditionPresentAndEqual(co.Status.Conditions, cond) {
+			co.Status.Versions = []configv1.OperandVersion{{Name: controllers.OperatorVersionKey, Version: r.ReleaseVersion}}
+			log.V(2).Info("syncing status: components not available", "message", cond.Message)
+			return r.SyncStatus(ctx, co, conds)
+		}
+	}
+
+	return nil
+}
+
+// SetControllerAvailable sets the Available and Degraded conditions of the given controller, e.g. InfraClusterController,
+// to report it works as expected. The Available and Degraded conditions of the ClusterOperator are left to the
+// components, which include the controllers.
+func (r *ClusterOperatorStatusClient) SetControllerAvailable(ctx context.Context, controller string) error {
+	conds := []configv1.ClusterOperatorStatusCondition{
+		NewClusterOperatorStatusCondition(configv1.ClusterStatusConditionType(controller+controllerAvailableSuffix), configv1.ConditionTrue,
+			ReasonAsExpected, fmt.Sprintf("%s works as expected", controller)),
+		NewClusterOperatorStatusCondition(configv1.ClusterStatusConditionType(controller+controllerDegradedSuffix), configv1.ConditionFalse,
+			ReasonAsExpected, fmt.Sprintf("%s works as expected", controller)),
+	}
+
+	return r.syncControllerConditions(ctx, conds)
+}
+
+// SetControllerDegraded sets the Degraded condition of the given controller to True with the reconcile error.
+// The ClusterOperator is reported Degraded once the components are synced.
+func (r *ClusterOperatorStatusClient) SetControllerDegraded(ctx context.Context, controller string, reconcileErr error) error {
+	conds := []configv1.ClusterOperatorStatusCondition{
+		NewClusterOperatorStatusCondition(configv1.ClusterStatusConditionType(controller+controllerAvailableSuffix), configv1.ConditionFalse,
+			ReasonSyncFailed, reconcileErr.Error()),
+		NewClusterOperatorStatusCondition(configv1.ClusterStatusConditionType(controller+controllerDegradedSuffix), configv1.ConditionTrue,
+			ReasonSyncFailed, reconcileErr.Error()),
+	}
+
+	return r.syncControllerConditions(ctx, conds)
+}
+
+// syncControllerConditions updates the ClusterOperator only when one of the controller conditions changed.
+func (r *ClusterOperatorStatusClient) syncControllerConditions(ctx context.Context, conds []configv1.ClusterOperatorStatusCondition) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	co, err := r.GetOrCreateClusterOperator(ctx)
+	if err != nil {
+		log.Error(err, "unable to set controller status")
+		return err
+	}
+
+	for _, cond := range conds {
+		if !isStatusConditionPresentAndEqual(co.Status.Conditions, cond) {
+			if cond.Status == configv1.ConditionTrue && cond.Reason == ReasonSyncFailed {
+				r.Recorder.Eventf(co, corev1.EventTypeWarning, "Status degraded", cond.Message)
+			}
+			log.V(2).Info("syncing controller status", "condition", cond.Type, "status", cond.Status)
+			return r.SyncStatus(ctx, co, conds)
+		}
+	}
+
+	return nil
+}
+
+// controllerComponents returns a degraded component for every controller with a true Degraded condition.
+func controllerComponents(conditions []configv1.ClusterOperatorStatusCondition) []ComponentStatus {
+	components := []ComponentStatus{}
+	for _, cond := range conditions {
+		controller := strings.TrimSuffix(string(cond.Type), controllerDegradedSuffix)
+		if controller == string(cond.Type) || !strings.HasSuffix(controller, "Controller") || cond.Status != configv1.ConditionTrue {
+			continue
+		}
+
+		components = append(components, ComponentStatus{
+			Name:      controller,
+			Available: true,
+			Degraded:  true,
+			Message:   cond.Message,
+		})
+	}
+
+	return components
+}
+
+// componentConditions returns the cluster operator conditions for the given components,
+// or nil when every component is available and none is degraded.
+func componentConditions(components []ComponentStatus, releaseVersion string) []configv1.ClusterOperatorStatusCondition {
+	unavailable := []string{}
+	degraded := []string{}
+	for _, c := range components {
+		if !c.Available {
+			unavailable = append(unavailable, fmt.Sprintf("%s: %s", c.Name, c.Message))
+		}
+		if c.Degraded {
+			degraded = append(degraded, fmt.Sprintf("%s: %s", c.Name, c.Message))
+		}
+	}
+
+	if len(unavailable) == 0 && len(degraded) == 0 {
+		return nil
+	}
+
+	available := NewClusterOperatorStatusCondition(configv1.OperatorAvailable, configv1.ConditionTrue, ReasonAsExpected,
+		fmt.Sprintf("Cluster CAPI Operator is available at %s", releaseVersion))
+	progressing := NewClusterOperatorStatusCondition(configv1.OperatorProgressing, configv1.ConditionFalse, ReasonAsExpected, "")
+	if len(unavailable) > 0 {
+		message := fmt.Sprintf("Components are not available:\n%s", strings.Join(unavailable, "\n"))
+		available = NewClusterOperatorStatusCondition(configv1.OperatorAvailable, configv1.ConditionFalse, ReasonComponentsNotAvailable, message)
+		progressing = NewClusterOperatorStatusCondition(configv1.OperatorProgressing, configv1.ConditionTrue, ReasonSyncing, message)
+	}
+
+	degradedCond := NewClusterOperatorStatusCondition(configv1.OperatorDegraded, configv1.ConditionFalse, ReasonAsExpected, "")
+	upgradeable := NewClusterOperatorStatusCondition(configv1.OperatorUpgradeable, configv1.ConditionTrue, ReasonAsExpected, "")
+	if len(degraded) > 0 {
+		message := fmt.Sprintf("Components are degraded:\n%s", strings.Join(degraded, "\n"))
+		degradedCond = NewClusterOperatorStatusCondition(configv1.OperatorDegraded, configv1.ConditionTrue, ReasonComponentsDegraded, message)
+		progressing = NewClusterOperatorStatusCondition(configv1.OperatorProgressing, configv1.ConditionFalse, ReasonComponentsDegraded, "")
+		upgradeable = NewClusterOperatorStatusCondition(configv1.OperatorUpgradeable, configv1.ConditionFalse, ReasonComponentsDegraded, "")
+	}
+
+	return []configv1.ClusterOperatorStatusCondition{available, progressing, degradedCond, upgradeable}
+}
+
+// isStatusConditionPresentAndEqual compares the message as well as the status,
+// because the failing components can change while the condition status stays the same.
+func isStatusConditionPresentAndEqual(conditions []configv1.ClusterOperatorStatusCondition, cond configv1.ClusterOperatorStatusCondition) bool {
+	existing := v1helpers.FindStatusCondition(conditions, cond.Type)
+	return existing != nil && existing.Status == cond.Status && existing.Reason == cond.Reason && existing.Message == cond.Message
+}
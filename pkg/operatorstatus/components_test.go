@@ -0,0 +1,200 @@
+package operatorstatus
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/cluster-capi-operator/pkg/controllers"
+	"github.com/openshift/library-go/pkg/config/clusteroperator/v1helpers"
+)
+
+func newDeployment(name string, conditions ...appsv1.DeploymentCondition) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status:     appsv1.DeploymentStatus{Conditions: conditions},
+	}
+}
+
+func newPod(name string, containers ...corev1.ContainerStatus) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status:     corev1.PodStatus{ContainerStatuses: containers},
+	}
+}
+
+var _ = Describe("Deployment component status", func() {
+	It("should be available when the deployment is available", func() {
+		status := DeploymentComponentStatus(newDeployment("capi-controller-manager",
+			appsv1.DeploymentCondition{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue},
+			appsv1.DeploymentCondition{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionTrue},
+		))
+		Expect(status).To(Equal(ComponentStatus{Name: "Deployment/capi-controller-manager", Available: true}))
+	})
+
+	It("should be degraded when the deployment exceeded its progress deadline", func() {
+		status := DeploymentComponentStatus(newDeployment("capa-controller-manager",
+			appsv1.DeploymentCondition{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionFalse, Message: "Deployment does not have minimum availability."},
+			appsv1.DeploymentCondition{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionFalse, Reason: "ProgressDeadlineExceeded", Message: "ReplicaSet has timed out progressing."},
+		))
+		Expect(status.Available).To(BeFalse())
+		Expect(status.Degraded).To(BeTrue())
+		Expect(status.Message).To(Equal("ReplicaSet has timed out progressing."))
+	})
+
+	It("should be degraded when the deployment failed to create replicas", func() {
+		status := DeploymentComponentStatus(newDeployment("capa-controller-manager",
+			appsv1.DeploymentCondition{Type: appsv1.DeploymentReplicaFailure, Status: corev1.ConditionTrue, Message: "pods is forbidden"},
+		))
+		Expect(status.Available).To(BeFalse())
+		Expect(status.Degraded).To(BeTrue())
+		Expect(status.Message).To(Equal("pods is forbidden"))
+	})
+
+	It("should not be available without conditions", func() {
+		status := DeploymentComponentStatus(newDeployment("capi-controller-manager"))
+		Expect(status.Available).To(BeFalse())
+		Expect(status.Degraded).To(BeFalse())
+		Expect(status.Message).NotTo(BeEmpty())
+	})
+
+	It("should be degraded when a container cannot pull its image", func() {
+		status := DeploymentComponentStatus(newDeployment("capa-controller-manager",
+			appsv1.DeploymentCondition{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionFalse, Message: "Deployment does not have minimum availability."},
+		), newPod("capa-controller-manager-7d9f", corev1.ContainerStatus{
+			Name: "manager",
+			State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{
+				Reason:  "ImagePullBackOff",
+				Message: "Back-off pulling image \"quay.io/openshift/aws:broken\"",
+			}},
+		}))
+		Expect(status.Available).To(BeFalse())
+		Expect(status.Degraded).To(BeTrue())
+		Expect(status.Message).To(Equal("container manager of pod capa-controller-manager-7d9f is in ImagePullBackOff: Back-off pulling image \"quay.io/openshift/aws:broken\""))
+	})
+
+	It("should be degraded when a container is crash looping", func() {
+		status := DeploymentComponentStatus(newDeployment("capi-controller-manager"),
+			newPod("capi-controller-manager-5c8b", corev1.ContainerStatus{
+				Name:  "manager",
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+			}))
+		Expect(status.Degraded).To(BeTrue())
+		Expect(status.Message).To(Equal("container manager of pod capi-controller-manager-5c8b is in CrashLoopBackOff"))
+	})
+
+	It("should not be degraded while containers are being created", func() {
+		status := DeploymentComponentStatus(newDeployment("capi-controller-manager"),
+			newPod("capi-controller-manager-5c8b", corev1.ContainerStatus{
+				Name:  "manager",
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ContainerCreating"}},
+			}))
+		Expect(status.Degraded).To(BeFalse())
+		Expect(status.Message).To(Equal("waiting for deployment to become available"))
+	})
+})
+
+var _ = Describe("Component conditions", func() {
+	It("should return no conditions when every component is healthy", func() {
+		Expect(componentConditions([]ComponentStatus{
+			{Name: "Deployment/capi-controller-manager", Available: true},
+			{Name: "Deployment/capa-controller-manager", Available: true},
+		}, "4.14.0")).To(BeNil())
+	})
+
+	It("should name the failing component when one provider is crash looping", func() {
+		conds := componentConditions([]ComponentStatus{
+			{Name: "Deployment/capi-controller-manager", Available: true},
+			{Name: "Deployment/capa-controller-manager", Degraded: true, Message: "ReplicaSet has timed out progressing."},
+		}, "4.14.0")
+
+		available := v1helpers.FindStatusCondition(conds, configv1.OperatorAvailable)
+		Expect(available).NotTo(BeNil())
+		Expect(available.Status).To(Equal(configv1.ConditionFalse))
+		Expect(available.Message).To(ContainSubstring("Deployment/capa-controller-manager: ReplicaSet has timed out progressing."))
+		Expect(available.Message).NotTo(ContainSubstring("capi-controller-manager"))
+
+		degraded := v1helpers.FindStatusCondition(conds, configv1.OperatorDegraded)
+		Expect(degraded).NotTo(BeNil())
+		Expect(degraded.Status).To(Equal(configv1.ConditionTrue))
+		Expect(degraded.Reason).To(Equal(ReasonComponentsDegraded))
+		Expect(degraded.Message).To(ContainSubstring("Deployment/capa-controller-manager"))
+
+		Expect(v1helpers.IsStatusConditionFalse(conds, configv1.OperatorUpgradeable)).To(BeTrue())
+		Expect(v1helpers.IsStatusConditionFalse(conds, configv1.OperatorProgressing)).To(BeTrue())
+	})
+
+	It("should be progressing while a component is becoming available", func() {
+		conds := componentConditions([]ComponentStatus{
+			{Name: "Deployment/capi-controller-manager", Message: "deployment does not exist yet"},
+		}, "4.14.0")
+
+		Expect(v1helpers.IsStatusConditionFalse(conds, configv1.OperatorAvailable)).To(BeTrue())
+		Expect(v1helpers.IsStatusConditionTrue(conds, configv1.OperatorProgressing)).To(BeTrue())
+		Expect(v1helpers.IsStatusConditionFalse(conds, configv1.OperatorDegraded)).To(BeTrue())
+		Expect(v1helpers.IsStatusConditionTrue(conds, configv1.OperatorUpgradeable)).To(BeTrue())
+	})
+})
+
+var _ = Describe("Controller status", func() {
+	var r *ClusterOperatorStatusClient
+
+	ctx := context.Background()
+	healthy := []ComponentStatus{{Name: "Deployment/capi-controller-manager", Available: true}}
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(configv1.AddToScheme(scheme)).To(Succeed())
+
+		r = &ClusterOperatorStatusClient{
+			Client:         fake.NewClientBuilder().WithScheme(scheme).Build(),
+			Recorder:       record.NewFakeRecorder(32),
+			ReleaseVersion: "4.14.0",
+		}
+	})
+
+	getConditions := func() []configv1.ClusterOperatorStatusCondition {
+		co := &configv1.ClusterOperator{}
+		Expect(r.Get(ctx, client.ObjectKey{Name: controllers.ClusterOperatorName}, co)).To(Succeed())
+		return co.Status.Conditions
+	}
+
+	It("should stay degraded when a controller succeeds after a component failed", func() {
+		Expect(r.SetStatusFromComponents(ctx, []ComponentStatus{
+			{Name: "Deployment/capa-controller-manager", Degraded: true, Message: "container manager is in CrashLoopBackOff"},
+		})).To(Succeed())
+		Expect(r.SetControllerAvailable(ctx, "InfraClusterController")).To(Succeed())
+
+		conds := getConditions()
+		Expect(v1helpers.IsStatusConditionTrue(conds, configv1.OperatorDegraded)).To(BeTrue())
+		Expect(v1helpers.IsStatusConditionFalse(conds, configv1.OperatorAvailable)).To(BeTrue())
+		Expect(v1helpers.IsStatusConditionTrue(conds, "InfraClusterControllerAvailable")).To(BeTrue())
+	})
+
+	It("should report a degraded controller in the ClusterOperator conditions", func() {
+		Expect(r.SetControllerDegraded(ctx, "InfraClusterController", errors.New("unable to get credentials"))).To(Succeed())
+		Expect(r.SetStatusFromComponents(ctx, healthy)).To(Succeed())
+
+		conds := getConditions()
+		degraded := v1helpers.FindStatusCondition(conds, configv1.OperatorDegraded)
+		Expect(degraded).NotTo(BeNil())
+		Expect(degraded.Status).To(Equal(configv1.ConditionTrue))
+		Expect(degraded.Message).To(ContainSubstring("InfraClusterController: unable to get credentials"))
+		Expect(v1helpers.IsStatusConditionTrue(conds, configv1.OperatorAvailable)).To(BeTrue())
+
+		Expect(r.SetControllerAvailable(ctx, "InfraClusterController")).To(Succeed())
+		Expect(r.SetStatusFromComponents(ctx, healthy)).To(Succeed())
+
+		Expect(v1helpers.IsStatusConditionFalse(getConditions(), configv1.OperatorDegraded)).To(BeTrue())
+	})
+})
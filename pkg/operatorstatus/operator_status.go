@@ -11,6 +11,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/record"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -24,6 +25,9 @@ const (
 	ReasonInitializing = "Initializing"
 	ReasonSyncing      = "SyncingResources"
 	ReasonSyncFailed   = "SyncingFailed"
+
+	// infrastructureGroup is the API group of the CAPI infrastructure provider resources.
+	infrastructureGroup = "infrastructure.cluster.x-k8s.io"
 )
 
 type ClusterOperatorStatusClient struct {
@@ -31,6 +35,8 @@ type ClusterOperatorStatusClient struct {
 	Recorder         record.EventRecorder
 	ManagedNamespace string
 	ReleaseVersion   string
+	// PlatformType is the platform of the cluster, used to reference the platform specific CAPI resources.
+	PlatformType configv1.PlatformType
 }
 
 // setStatusAvailable sets the Available condition to True, with the given reason
@@ -143,16 +149,38 @@ func (r *ClusterOperatorStatusClient) SyncStatus(ctx context.Context, co *config
 	return r.Client.Status().Update(ctx, co)
 }
 
+// infraClusterResources maps a platform to the CAPI infrastructure cluster resources created for it.
+var infraClusterResources = map[configv1.PlatformType][]string{
+	configv1.AWSPlatformType:      {"awsclusters"},
+	configv1.AzurePlatformType:    {"azureclusters", "azureclusteridentities"},
+	configv1.GCPPlatformType:      {"gcpclusters"},
+	configv1.IBMCloudPlatformType: {"ibmvpcclusters"},
+	configv1.PowerVSPlatformType:  {"ibmpowervsclusters"},
+}
+
+// relatedObjects returns the objects collected by must-gather for the ClusterOperator.
+// An empty name references all objects of the resource in the namespace.
 func (r *ClusterOperatorStatusClient) relatedObjects() []configv1.ObjectReference {
-	// TBD: Add an actual set of object references from getResources method
-	return []configv1.ObjectReference{
+	objects := []configv1.ObjectReference{
 		{Resource: "namespaces", Name: controllers.DefaultManagedNamespace},
 		{Group: configv1.GroupName, Resource: "clusteroperators", Name: controllers.ClusterOperatorName},
 		{Resource: "namespaces", Name: r.ManagedNamespace},
 		{Group: "", Resource: "serviceaccounts", Name: "cluster-capi-operator"},
 		{Group: "", Resource: "configmaps", Name: "cluster-capi-operator-images"},
 		{Group: "apps", Resource: "deployments", Name: "cluster-capi-operator"},
+		{Group: "apps", Resource: "deployments", Namespace: r.ManagedNamespace, Name: ""},
+		{Group: clusterv1.GroupVersion.Group, Resource: "clusters", Namespace: r.ManagedNamespace, Name: ""},
+		{Group: clusterv1.GroupVersion.Group, Resource: "machinesets", Namespace: r.ManagedNamespace, Name: ""},
+		{Group: clusterv1.GroupVersion.Group, Resource: "machines", Namespace: r.ManagedNamespace, Name: ""},
 	}
+
+	for _, resource := range infraClusterResources[r.PlatformType] {
+		objects = append(objects, configv1.ObjectReference{
+			Group: infrastructureGroup, Resource: resource, Namespace: r.ManagedNamespace, Name: "",
+		})
+	}
+
+	return objects
 }
 
 func NewClusterOperatorStatusCondition(conditionType configv1.ClusterStatusConditionType,
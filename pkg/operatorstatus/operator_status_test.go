@@ -0,0 +1,71 @@
+package operatorstatus
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/cluster-capi-operator/pkg/controllers"
+)
+
+var _ = Describe("Related objects", func() {
+	commonObjects := []configv1.ObjectReference{
+		{Group: "apps", Resource: "deployments", Namespace: controllers.DefaultManagedNamespace},
+		{Group: "cluster.x-k8s.io", Resource: "clusters", Namespace: controllers.DefaultManagedNamespace},
+		{Group: "cluster.x-k8s.io", Resource: "machinesets", Namespace: controllers.DefaultManagedNamespace},
+		{Group: "cluster.x-k8s.io", Resource: "machines", Namespace: controllers.DefaultManagedNamespace},
+	}
+
+	DescribeTable("should reference the CAPI resources of the platform",
+		func(platform configv1.PlatformType, infraObjects []configv1.ObjectReference, unexpected []configv1.ObjectReference) {
+			r := &ClusterOperatorStatusClient{
+				ManagedNamespace: controllers.DefaultManagedNamespace,
+				PlatformType:     platform,
+			}
+
+			objects := r.relatedObjects()
+			Expect(objects).To(ContainElement(configv1.ObjectReference{Group: configv1.GroupName, Resource: "clusteroperators", Name: controllers.ClusterOperatorName}))
+			Expect(objects).To(ContainElements(commonObjects))
+			Expect(objects).To(ContainElements(infraObjects))
+			for _, object := range unexpected {
+				Expect(objects).NotTo(ContainElement(object))
+			}
+		},
+		Entry("AWS", configv1.AWSPlatformType,
+			[]configv1.ObjectReference{
+				{Group: "infrastructure.cluster.x-k8s.io", Resource: "awsclusters", Namespace: controllers.DefaultManagedNamespace},
+			},
+			[]configv1.ObjectReference{
+				{Group: "infrastructure.cluster.x-k8s.io", Resource: "gcpclusters", Namespace: controllers.DefaultManagedNamespace},
+			},
+		),
+		Entry("Azure", configv1.AzurePlatformType,
+			[]configv1.ObjectReference{
+				{Group: "infrastructure.cluster.x-k8s.io", Resource: "azureclusters", Namespace: controllers.DefaultManagedNamespace},
+				{Group: "infrastructure.cluster.x-k8s.io", Resource: "azureclusteridentities", Namespace: controllers.DefaultManagedNamespace},
+			},
+			[]configv1.ObjectReference{
+				{Group: "infrastructure.cluster.x-k8s.io", Resource: "awsclusters", Namespace: controllers.DefaultManagedNamespace},
+			},
+		),
+		Entry("GCP", configv1.GCPPlatformType,
+			[]configv1.ObjectReference{
+				{Group: "infrastructure.cluster.x-k8s.io", Resource: "gcpclusters", Namespace: controllers.DefaultManagedNamespace},
+			},
+			[]configv1.ObjectReference{
+				{Group: "infrastructure.cluster.x-k8s.io", Resource: "awsclusters", Namespace: controllers.DefaultManagedNamespace},
+			},
+		),
+	)
+
+	It("should not reference infrastructure cluster resources on unsupported platforms", func() {
+		r := &ClusterOperatorStatusClient{
+			ManagedNamespace: controllers.DefaultManagedNamespace,
+			PlatformType:     configv1.NonePlatformType,
+		}
+
+		for _, object := range r.relatedObjects() {
+			Expect(object.Group).NotTo(Equal("infrastructure.cluster.x-k8s.io"))
+		}
+	})
+})
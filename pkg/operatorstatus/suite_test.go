@@ -0,0 +1,13 @@
+package operatorstatus
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestOperatorStatus(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Operator Status Suite")
+}
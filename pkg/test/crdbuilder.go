@@ -46,10 +46,33 @@ var (
 	// fakeAWSClusterCRD is a fake AzureCluster CRD.
 	fakeAzureClusterCRD = generateCRD(infrastructureGroupVersion.WithKind(fakeAzureClusterKind))
 
+	// fakeAzureClusterIdentityKind is the Kind for the AzureClusterIdentity.
+	fakeAzureClusterIdentityKind = "AzureClusterIdentity"
+	// fakeAzureClusterIdentityCRD is a fake AzureClusterIdentity CRD.
+	fakeAzureClusterIdentityCRD = generateCRD(infrastructureGroupVersion.WithKind(fakeAzureClusterIdentityKind))
+
 	// fakeGCPClusterKind is the Kind for the GCPCluster.
 	fakeGCPClusterKind = "GCPCluster"
 	// fakeGCPClusterCRD is a fake GCPCluster CRD.
 	fakeGCPClusterCRD = generateCRD(infrastructureGroupVersion.WithKind(fakeGCPClusterKind))
+
+	// machineGroupVersion is group version used for Machine API objects.
+	machineGroupVersion = schema.GroupVersion{Group: "machine.openshift.io", Version: "v1beta1"}
+
+	// fakeMachineSetKind is the Kind for the Machine API MachineSet.
+	fakeMachineSetKind = "MachineSet"
+	// fakeMachineSetCRD is a fake Machine API MachineSet CRD.
+	fakeMachineSetCRD = generateCRD(machineGroupVersion.WithKind(fakeMachineSetKind))
+
+	// fakeMachineKind is the Kind for the Machine API Machine.
+	fakeMachineKind = "Machine"
+	// fakeMachineCRD is a fake Machine API Machine CRD.
+	fakeMachineCRD = generateCRD(machineGroupVersion.WithKind(fakeMachineKind))
+
+	// fakeIBMVPCClusterKind is the Kind for the IBMVPCCluster.
+	fakeIBMVPCClusterKind = "IBMVPCCluster"
+	// fakeIBMVPCClusterCRD is a fake IBMVPCCluster CRD.
+	fakeIBMVPCClusterCRD = generateCRD(infrastructureGroupVersion.WithKind(fakeIBMVPCClusterKind))
 )
 
 func generateCRD(gvk schema.GroupVersionKind) *apiextensionsv1.CustomResourceDefinition {
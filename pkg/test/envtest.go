@@ -13,6 +13,7 @@ import (
 	awsv1 "sigs.k8s.io/cluster-api-provider-aws/v2/api/v1beta1"
 	azurev1 "sigs.k8s.io/cluster-api-provider-azure/api/v1beta1"
 	gcpv1 "sigs.k8s.io/cluster-api-provider-gcp/api/v1beta1"
+	ibmcloudv1 "sigs.k8s.io/cluster-api-provider-ibmcloud/api/v1beta1"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
@@ -29,6 +30,7 @@ func init() {
 	utilruntime.Must(awsv1.AddToScheme(scheme.Scheme))
 	utilruntime.Must(azurev1.AddToScheme(scheme.Scheme))
 	utilruntime.Must(gcpv1.AddToScheme(scheme.Scheme))
+	utilruntime.Must(ibmcloudv1.AddToScheme(scheme.Scheme))
 	utilruntime.Must(clusterv1.AddToScheme(scheme.Scheme))
 }
 
@@ -43,7 +45,11 @@ func StartEnvTest(testEnv *envtest.Environment) (*rest.Config, client.Client, er
 		fakeClusterCRD,
 		fakeAWSClusterCRD,
 		fakeAzureClusterCRD,
+		fakeAzureClusterIdentityCRD,
 		fakeGCPClusterCRD,
+		fakeIBMVPCClusterCRD,
+		fakeMachineSetCRD,
+		fakeMachineCRD,
 	}
 	testEnv.CRDDirectoryPaths = []string{
 		path.Join(root, "vendor", "github.com", "openshift", "api", "config", "v1"),
@@ -0,0 +1,52 @@
+package util
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+)
+
+// WithoutManagedFields wraps newCache so that the managed fields are dropped from every cached object.
+// The controllers never read them, and they are often the largest part of an object.
+func WithoutManagedFields(newCache cache.NewCacheFunc) cache.NewCacheFunc {
+	return func(config *rest.Config, opts cache.Options) (cache.Cache, error) {
+		opts.DefaultTransform = StripManagedFields
+		return newCache(config, opts)
+	}
+}
+
+// WithLabeledPods wraps newCache so that only pods carrying the given label are cached. Watching pods otherwise
+// caches every pod of the cached namespaces, while the controllers only read the pods of the providers.
+func WithLabeledPods(newCache cache.NewCacheFunc, label string) cache.NewCacheFunc {
+	return func(config *rest.Config, opts cache.Options) (cache.Cache, error) {
+		requirement, err := labels.NewRequirement(label, selection.Exists, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		selectors := cache.SelectorsByObject{}
+		for obj, selector := range opts.SelectorsByObject {
+			selectors[obj] = selector
+		}
+		selectors[&corev1.Pod{}] = cache.ObjectSelector{Label: labels.NewSelector().Add(*requirement)}
+		opts.SelectorsByObject = selectors
+
+		return newCache(config, opts)
+	}
+}
+
+// StripManagedFields is a cache transform removing the managed fields of an object.
+// Values that are not objects, such as deletion tombstones, are returned unchanged.
+func StripManagedFields(obj interface{}) (interface{}, error) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return obj, nil
+	}
+
+	accessor.SetManagedFields(nil)
+
+	return obj, nil
+}
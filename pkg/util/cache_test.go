@@ -0,0 +1,64 @@
+package util
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/rest"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+)
+
+var _ = Describe("Labeled pods cache", func() {
+	It("should only select pods carrying the label", func() {
+		var got cache.Options
+		newCache := WithLabeledPods(func(_ *rest.Config, opts cache.Options) (cache.Cache, error) {
+			got = opts
+			return nil, nil
+		}, ProviderLabel)
+
+		_, err := newCache(&rest.Config{}, cache.Options{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(got.SelectorsByObject).To(HaveLen(1))
+
+		for obj, selector := range got.SelectorsByObject {
+			Expect(obj).To(BeAssignableToTypeOf(&corev1.Pod{}))
+			Expect(selector.Label.Matches(labels.Set{ProviderLabel: "cluster-api"})).To(BeTrue())
+			Expect(selector.Label.Matches(labels.Set{"app": "other"})).To(BeFalse())
+		}
+	})
+})
+
+var _ = Describe("Strip managed fields", func() {
+	managedFields := []metav1.ManagedFieldsEntry{{Manager: "test", Operation: metav1.ManagedFieldsOperationApply}}
+
+	It("should remove the managed fields of a typed object", func() {
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "test", ManagedFields: managedFields}}
+
+		obj, err := StripManagedFields(secret)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(obj.(*corev1.Secret).ManagedFields).To(BeEmpty())
+		Expect(obj.(*corev1.Secret).Name).To(Equal("test"))
+	})
+
+	It("should remove the managed fields of an unstructured object", func() {
+		u := &unstructured.Unstructured{}
+		u.SetName("test")
+		u.SetManagedFields(managedFields)
+
+		obj, err := StripManagedFields(u)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(obj.(*unstructured.Unstructured).GetManagedFields()).To(BeEmpty())
+	})
+
+	It("should return deletion tombstones unchanged", func() {
+		tombstone := toolscache.DeletedFinalStateUnknown{Key: "test"}
+
+		obj, err := StripManagedFields(tombstone)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(obj).To(Equal(tombstone))
+	})
+})
@@ -0,0 +1,45 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+)
+
+// cacheSyncCheckTimeout bounds how long a single readiness request waits for the informers to sync.
+const cacheSyncCheckTimeout = time.Second
+
+// cacheSyncWaiter is the subset of the cache used to check whether its informers have synced.
+type cacheSyncWaiter interface {
+	WaitForCacheSync(ctx context.Context) bool
+}
+
+// NewCacheSyncCheck returns a readiness check that passes once the informers of the cache have synced.
+// Once synced, the check keeps passing without querying the cache again.
+func NewCacheSyncCheck(c cacheSyncWaiter) healthz.Checker {
+	var mu sync.Mutex
+	synced := false
+
+	return func(req *http.Request) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if synced {
+			return nil
+		}
+
+		ctx, cancel := context.WithTimeout(req.Context(), cacheSyncCheckTimeout)
+		defer cancel()
+
+		if !c.WaitForCacheSync(ctx) {
+			return errors.New("informer caches are not synced yet")
+		}
+		synced = true
+
+		return nil
+	}
+}
@@ -0,0 +1,42 @@
+package util
+
+import (
+	"context"
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type fakeCacheSyncWaiter struct {
+	synced bool
+	calls  int
+}
+
+func (f *fakeCacheSyncWaiter) WaitForCacheSync(_ context.Context) bool {
+	f.calls++
+	return f.synced
+}
+
+var _ = Describe("Cache sync check", func() {
+	req, _ := http.NewRequest(http.MethodGet, "/readyz", nil)
+
+	It("should fail until the caches are synced", func() {
+		waiter := &fakeCacheSyncWaiter{}
+		check := NewCacheSyncCheck(waiter)
+
+		Expect(check(req)).NotTo(Succeed())
+
+		waiter.synced = true
+		Expect(check(req)).To(Succeed())
+	})
+
+	It("should not wait for the caches again once synced", func() {
+		waiter := &fakeCacheSyncWaiter{synced: true}
+		check := NewCacheSyncCheck(waiter)
+
+		Expect(check(req)).To(Succeed())
+		Expect(check(req)).To(Succeed())
+		Expect(waiter.calls).To(Equal(1))
+	})
+})
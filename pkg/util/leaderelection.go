@@ -0,0 +1,113 @@
+package util
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/component-base/config"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// SetLeaderElectionOptions sets the leader election options of the manager from the parsed leader election configuration.
+// When leader election is disabled, the remaining options are left unset, so no lock namespace has to exist,
+// e.g. when running the operator locally.
+func SetLeaderElectionOptions(opts *manager.Options, cfg config.LeaderElectionConfiguration) {
+	opts.LeaderElection = cfg.LeaderElect
+	if !cfg.LeaderElect {
+		return
+	}
+
+	opts.LeaderElectionNamespace = cfg.ResourceNamespace
+	opts.LeaderElectionID = cfg.ResourceName
+	opts.LeaderElectionResourceLock = cfg.ResourceLock
+	opts.LeaseDuration = &cfg.LeaseDuration.Duration
+	opts.RenewDeadline = &cfg.RenewDeadline.Duration
+	opts.RetryPeriod = &cfg.RetryPeriod.Duration
+}
+
+// LeaderElectionStatus tracks whether this replica holds the leader election lease. It is installed as the
+// client-go leader election metrics provider, reports the leader_election_master_status metric and fails its
+// health check once the replica lost a lease it held, so a replica that stopped leading is restarted.
+type LeaderElectionStatus struct {
+	gauge *prometheus.GaugeVec
+
+	mu      sync.Mutex
+	leading map[string]bool
+	lost    map[string]bool
+}
+
+var (
+	_ leaderelection.MetricsProvider = &LeaderElectionStatus{}
+	_ prometheus.Collector           = &LeaderElectionStatus{}
+)
+
+// NewLeaderElectionStatus returns a leader election status reporting no lease held.
+func NewLeaderElectionStatus() *LeaderElectionStatus {
+	return &LeaderElectionStatus{
+		gauge: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "leader_election_master_status",
+			Help: "Gauge of if the reporting system is master of the relevant lease, 0 indicates backup, 1 indicates master. 'name' is the string used to identify the lease.",
+		}, []string{"name"}),
+		leading: map[string]bool{},
+		lost:    map[string]bool{},
+	}
+}
+
+// NewLeaderMetric implements leaderelection.MetricsProvider.
+func (s *LeaderElectionStatus) NewLeaderMetric() leaderelection.SwitchMetric {
+	return s
+}
+
+// On implements leaderelection.SwitchMetric and is called when the lease is acquired.
+func (s *LeaderElectionStatus) On(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.gauge.WithLabelValues(name).Set(1)
+	s.leading[name] = true
+}
+
+// Off implements leaderelection.SwitchMetric and is called when the elector starts and when the lease is lost.
+func (s *LeaderElectionStatus) Off(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.gauge.WithLabelValues(name).Set(0)
+	if s.leading[name] {
+		s.lost[name] = true
+	}
+	s.leading[name] = false
+}
+
+// Check implements healthz.Checker. Replicas waiting for the lease are healthy.
+func (s *LeaderElectionStatus) Check(_ *http.Request) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lost := []string{}
+	for name := range s.lost {
+		lost = append(lost, name)
+	}
+
+	if len(lost) > 0 {
+		sort.Strings(lost)
+		return fmt.Errorf("lost leader election lease: %s", strings.Join(lost, ", "))
+	}
+
+	return nil
+}
+
+// Describe implements prometheus.Collector.
+func (s *LeaderElectionStatus) Describe(ch chan<- *prometheus.Desc) {
+	s.gauge.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (s *LeaderElectionStatus) Collect(ch chan<- prometheus.Metric) {
+	s.gauge.Collect(ch)
+}
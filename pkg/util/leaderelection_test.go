@@ -0,0 +1,96 @@
+package util
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	dto "github.com/prometheus/client_model/go"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/component-base/config"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+var _ = Describe("Set leader election options", func() {
+	var cfg config.LeaderElectionConfiguration
+
+	BeforeEach(func() {
+		cfg = config.LeaderElectionConfiguration{
+			LeaderElect:       true,
+			LeaseDuration:     LeaseDuration,
+			RenewDeadline:     RenewDeadline,
+			RetryPeriod:       RetryPeriod,
+			ResourceLock:      resourcelock.LeasesResourceLock,
+			ResourceName:      "cluster-capi-operator-leader",
+			ResourceNamespace: "openshift-cluster-api",
+		}
+	})
+
+	It("should set all leader election options when leader election is enabled", func() {
+		opts := manager.Options{}
+		SetLeaderElectionOptions(&opts, cfg)
+
+		Expect(opts.LeaderElection).To(BeTrue())
+		Expect(opts.LeaderElectionNamespace).To(Equal("openshift-cluster-api"))
+		Expect(opts.LeaderElectionID).To(Equal("cluster-capi-operator-leader"))
+		Expect(opts.LeaderElectionResourceLock).To(Equal(resourcelock.LeasesResourceLock))
+		Expect(*opts.LeaseDuration).To(Equal(137 * time.Second))
+		Expect(*opts.RenewDeadline).To(Equal(107 * time.Second))
+		Expect(*opts.RetryPeriod).To(Equal(26 * time.Second))
+	})
+
+	It("should use a configured resource lock", func() {
+		cfg.ResourceLock = resourcelock.ConfigMapsLeasesResourceLock
+		opts := manager.Options{}
+		SetLeaderElectionOptions(&opts, cfg)
+
+		Expect(opts.LeaderElectionResourceLock).To(Equal(resourcelock.ConfigMapsLeasesResourceLock))
+	})
+
+	It("should not require a namespace when leader election is disabled", func() {
+		cfg.LeaderElect = false
+		opts := manager.Options{}
+		SetLeaderElectionOptions(&opts, cfg)
+
+		Expect(opts.LeaderElection).To(BeFalse())
+		Expect(opts.LeaderElectionNamespace).To(BeEmpty())
+		Expect(opts.LeaderElectionID).To(BeEmpty())
+		Expect(opts.LeaderElectionResourceLock).To(BeEmpty())
+		Expect(opts.LeaseDuration).To(BeNil())
+	})
+})
+
+func leaderGauge(status *LeaderElectionStatus) float64 {
+	metric := &dto.Metric{}
+	Expect(status.gauge.WithLabelValues("cluster-capi-operator-leader").Write(metric)).To(Succeed())
+	return metric.GetGauge().GetValue()
+}
+
+var _ = Describe("Leader election status", func() {
+	var status *LeaderElectionStatus
+
+	BeforeEach(func() {
+		status = NewLeaderElectionStatus()
+		status.Off("cluster-capi-operator-leader")
+	})
+
+	It("should report a replica waiting for the lease as healthy backup", func() {
+		Expect(status.Check(nil)).To(Succeed())
+		Expect(leaderGauge(status)).To(BeZero())
+	})
+
+	It("should report the leader", func() {
+		status.On("cluster-capi-operator-leader")
+
+		Expect(status.Check(nil)).To(Succeed())
+		Expect(leaderGauge(status)).To(Equal(1.0))
+	})
+
+	It("should fail the health check once the lease is lost", func() {
+		status.On("cluster-capi-operator-leader")
+		status.Off("cluster-capi-operator-leader")
+
+		Expect(status.Check(nil)).To(MatchError(ContainSubstring("cluster-capi-operator-leader")))
+		Expect(leaderGauge(status)).To(BeZero())
+	})
+})
@@ -0,0 +1,40 @@
+package util
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/go-logr/logr"
+	"github.com/go-logr/zapr"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"k8s.io/klog/v2/klogr"
+)
+
+const (
+	// LoggingFormatText logs through klog in its text format.
+	LoggingFormatText = "text"
+	// LoggingFormatJSON logs JSON lines through zap.
+	LoggingFormatJSON = "json"
+)
+
+// NewLogger returns a logger for the given logging format. JSON logs are written to out using the zap
+// production encoder with RFC3339 timestamps, and only include messages up to the given verbosity,
+// matching the klog -v flag.
+func NewLogger(format string, verbosity int, out io.Writer) (logr.Logger, error) {
+	switch format {
+	case LoggingFormatText:
+		return klogr.New(), nil
+	case LoggingFormatJSON:
+		encoderConfig := zap.NewProductionEncoderConfig()
+		encoderConfig.EncodeTime = zapcore.RFC3339TimeEncoder
+
+		// logr verbosity levels map to negative zap levels.
+		level := zap.NewAtomicLevelAt(zapcore.Level(-verbosity))
+		core := zapcore.NewCore(zapcore.NewJSONEncoder(encoderConfig), zapcore.AddSync(out), level)
+
+		return zapr.NewLogger(zap.New(core, zap.AddCaller(), zap.ErrorOutput(zapcore.AddSync(out)))), nil
+	default:
+		return logr.Logger{}, fmt.Errorf("unsupported logging format %q, must be one of %q or %q", format, LoggingFormatText, LoggingFormatJSON)
+	}
+}
@@ -0,0 +1,66 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("New logger", func() {
+	It("should log JSON lines with the expected keys in json mode", func() {
+		out := &bytes.Buffer{}
+		logger, err := NewLogger(LoggingFormatJSON, 2, out)
+		Expect(err).NotTo(HaveOccurred())
+
+		logger.WithName("test").Info("reconciling", "name", "cluster")
+		logger.Error(errors.New("boom"), "failed")
+
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		Expect(lines).To(HaveLen(2))
+
+		entry := map[string]interface{}{}
+		Expect(json.Unmarshal([]byte(lines[0]), &entry)).To(Succeed())
+		Expect(entry).To(HaveKeyWithValue("level", "info"))
+		Expect(entry).To(HaveKeyWithValue("logger", "test"))
+		Expect(entry).To(HaveKeyWithValue("msg", "reconciling"))
+		Expect(entry).To(HaveKeyWithValue("name", "cluster"))
+		Expect(entry).To(HaveKey("caller"))
+		Expect(entry).To(HaveKey("ts"))
+		_, err = time.Parse(time.RFC3339, entry["ts"].(string))
+		Expect(err).NotTo(HaveOccurred())
+
+		entry = map[string]interface{}{}
+		Expect(json.Unmarshal([]byte(lines[1]), &entry)).To(Succeed())
+		Expect(entry).To(HaveKeyWithValue("level", "error"))
+		Expect(entry).To(HaveKeyWithValue("error", "boom"))
+	})
+
+	It("should only log messages up to the verbosity in json mode", func() {
+		out := &bytes.Buffer{}
+		logger, err := NewLogger(LoggingFormatJSON, 2, out)
+		Expect(err).NotTo(HaveOccurred())
+
+		logger.V(2).Info("visible")
+		logger.V(3).Info("hidden")
+
+		Expect(out.String()).To(ContainSubstring("visible"))
+		Expect(out.String()).NotTo(ContainSubstring("hidden"))
+	})
+
+	It("should default to klog in text mode", func() {
+		out := &bytes.Buffer{}
+		_, err := NewLogger(LoggingFormatText, 0, out)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.String()).To(BeEmpty())
+	})
+
+	It("should reject unknown formats", func() {
+		_, err := NewLogger("yaml", 0, &bytes.Buffer{})
+		Expect(err).To(HaveOccurred())
+	})
+})
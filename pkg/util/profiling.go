@@ -0,0 +1,91 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"time"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+const profilingShutdownTimeout = 10 * time.Second
+
+// runnableAdder is the subset of the manager used to register runnables.
+type runnableAdder interface {
+	Add(manager.Runnable) error
+}
+
+// ProfilingServer serves the net/http/pprof and expvar handlers on its own listener.
+// It runs on every replica, not only on the leader.
+type ProfilingServer struct {
+	Addr string
+}
+
+// AddProfilingServer adds a profiling server listening on addr to the manager.
+// Profiling is disabled when addr is empty.
+func AddProfilingServer(mgr runnableAdder, addr string) error {
+	if addr == "" {
+		return nil
+	}
+
+	return mgr.Add(&ProfilingServer{Addr: addr})
+}
+
+// Start serves the profiling endpoints until the context is cancelled, then shuts the server down gracefully.
+func (s *ProfilingServer) Start(ctx context.Context) error {
+	log := ctrl.LoggerFrom(ctx).WithName("profiling")
+
+	listener, err := net.Listen("tcp", s.Addr)
+	if err != nil {
+		return fmt.Errorf("unable to listen on profiling address %s: %v", s.Addr, err)
+	}
+
+	server := &http.Server{
+		Handler:           s.handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		log.Info("serving profiling endpoints", "addr", listener.Addr().String())
+		serveErr <- server.Serve(listener)
+	}()
+
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("profiling server failed: %v", err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), profilingShutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("unable to shut down profiling server: %v", err)
+	}
+
+	return nil
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable, so profiles can be taken from any replica.
+func (s *ProfilingServer) NeedLeaderElection() bool {
+	return false
+}
+
+func (s *ProfilingServer) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+
+	return mux
+}
@@ -0,0 +1,73 @@
+package util
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+type fakeRunnableAdder struct {
+	runnables []manager.Runnable
+}
+
+func (f *fakeRunnableAdder) Add(runnable manager.Runnable) error {
+	f.runnables = append(f.runnables, runnable)
+	return nil
+}
+
+var _ = Describe("Profiling server", func() {
+	It("should not be added when the address is empty", func() {
+		mgr := &fakeRunnableAdder{}
+		Expect(AddProfilingServer(mgr, "")).To(Succeed())
+		Expect(mgr.runnables).To(BeEmpty())
+	})
+
+	It("should be added when an address is set", func() {
+		mgr := &fakeRunnableAdder{}
+		Expect(AddProfilingServer(mgr, "127.0.0.1:6060")).To(Succeed())
+		Expect(mgr.runnables).To(ConsistOf(&ProfilingServer{Addr: "127.0.0.1:6060"}))
+	})
+
+	It("should serve the pprof and expvar endpoints", func() {
+		server := httptest.NewServer((&ProfilingServer{}).handler())
+		defer server.Close()
+
+		for _, path := range []string{"/debug/pprof/", "/debug/pprof/goroutine", "/debug/vars"} {
+			resp, err := http.Get(server.URL + path)
+			Expect(err).NotTo(HaveOccurred())
+			resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusOK), path)
+		}
+	})
+
+	It("should serve until the context is cancelled", func() {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		addr := listener.Addr().String()
+		Expect(listener.Close()).To(Succeed())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		stopped := make(chan error, 1)
+		go func() {
+			stopped <- (&ProfilingServer{Addr: addr}).Start(ctx)
+		}()
+
+		Eventually(func() (int, error) {
+			resp, err := http.Get("http://" + addr + "/debug/pprof/")
+			if err != nil {
+				return 0, err
+			}
+			resp.Body.Close()
+			return resp.StatusCode, nil
+		}, 5*time.Second).Should(Equal(http.StatusOK))
+
+		cancel()
+		Eventually(stopped, 5*time.Second).Should(Receive(BeNil()))
+	})
+})
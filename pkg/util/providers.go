@@ -0,0 +1,75 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+const (
+	// ProviderLabel is set by the upstream CAPI operator on every provider component, including the pod templates
+	// of the provider Deployments.
+	ProviderLabel = "cluster.x-k8s.io/provider"
+
+	// ProviderOverridesConfigMapName is the optional, user owned ConfigMap in the managed namespace that overrides
+	// the supported providers. Its enabled and disabled keys hold comma separated platform names, e.g. "aws,gcp".
+	ProviderOverridesConfigMapName = "cluster-capi-operator-provider-overrides"
+	EnabledProvidersKey            = "enabled"
+	DisabledProvidersKey           = "disabled"
+)
+
+// IsProviderEnabled reports whether the infrastructure provider of the given platform is enabled. A platform listed
+// as disabled in the provider overrides ConfigMap is never enabled, while a platform listed as enabled is enabled even
+// when it is not part of the supported providers.
+func IsProviderEnabled(ctx context.Context, cl client.Reader, namespace string, supported map[string]bool, platform configv1.PlatformType) (bool, error) {
+	name := strings.ToLower(string(platform))
+	if name == "" {
+		return false, nil
+	}
+
+	cm := &corev1.ConfigMap{}
+	if err := cl.Get(ctx, client.ObjectKey{Namespace: namespace, Name: ProviderOverridesConfigMapName}, cm); k8serrors.IsNotFound(err) {
+		return supported[name], nil
+	} else if err != nil {
+		return false, fmt.Errorf("unable to get provider overrides ConfigMap: %v", err)
+	}
+
+	enabled := platformSet(cm.Data[EnabledProvidersKey])
+	disabled := platformSet(cm.Data[DisabledProvidersKey])
+	for platform := range enabled {
+		if disabled[platform] {
+			return false, fmt.Errorf("platform %s is both enabled and disabled in ConfigMap %s", platform, ProviderOverridesConfigMapName)
+		}
+	}
+
+	switch {
+	case disabled[name]:
+		return false, nil
+	case enabled[name]:
+		return true, nil
+	default:
+		return supported[name], nil
+	}
+}
+
+// IsProviderOverridesConfigMap reports whether the object is the provider overrides ConfigMap of the given namespace.
+func IsProviderOverridesConfigMap(obj client.Object, namespace string) bool {
+	return obj.GetNamespace() == namespace && obj.GetName() == ProviderOverridesConfigMapName
+}
+
+// platformSet parses a comma separated list of platform names.
+func platformSet(value string) map[string]bool {
+	platforms := map[string]bool{}
+	for _, platform := range strings.Split(value, ",") {
+		if platform = strings.ToLower(strings.TrimSpace(platform)); platform != "" {
+			platforms[platform] = true
+		}
+	}
+	return platforms
+}
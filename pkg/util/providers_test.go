@@ -0,0 +1,57 @@
+package util
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+var _ = Describe("Provider enablement", func() {
+	const namespace = "openshift-cluster-api"
+	ctx := context.Background()
+	supported := map[string]bool{"aws": true, "gcp": true}
+
+	overrides := func(data map[string]string) client.Reader {
+		return fake.NewClientBuilder().WithObjects(&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: ProviderOverridesConfigMapName, Namespace: namespace},
+			Data:       data,
+		}).Build()
+	}
+
+	DescribeTable("should resolve whether the provider is enabled",
+		func(cl client.Reader, platform configv1.PlatformType, expected bool) {
+			enabled, err := IsProviderEnabled(ctx, cl, namespace, supported, platform)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(enabled).To(Equal(expected))
+		},
+		Entry("supported platform without overrides", fake.NewClientBuilder().Build(), configv1.AWSPlatformType, true),
+		Entry("unsupported platform without overrides", fake.NewClientBuilder().Build(), configv1.AzurePlatformType, false),
+		Entry("empty platform", fake.NewClientBuilder().Build(), configv1.PlatformType(""), false),
+		Entry("disabled supported platform", overrides(map[string]string{DisabledProvidersKey: "gcp, AWS"}), configv1.AWSPlatformType, false),
+		Entry("enabled unsupported platform", overrides(map[string]string{EnabledProvidersKey: "azure"}), configv1.AzurePlatformType, true),
+		Entry("platform not listed in the overrides", overrides(map[string]string{DisabledProvidersKey: "gcp"}), configv1.AWSPlatformType, true),
+	)
+
+	It("should reject a platform that is both enabled and disabled", func() {
+		_, err := IsProviderEnabled(ctx, overrides(map[string]string{EnabledProvidersKey: "aws", DisabledProvidersKey: "aws"}), namespace, supported, configv1.AWSPlatformType)
+		Expect(err).To(MatchError(ContainSubstring("platform aws is both enabled and disabled")))
+	})
+
+	It("should ignore the overrides ConfigMap of other namespaces", func() {
+		cl := fake.NewClientBuilder().WithObjects(&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: ProviderOverridesConfigMapName, Namespace: "other"},
+			Data:       map[string]string{DisabledProvidersKey: "aws"},
+		}).Build()
+
+		enabled, err := IsProviderEnabled(ctx, cl, namespace, supported, configv1.AWSPlatformType)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(enabled).To(BeTrue())
+	})
+})
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"gopkg.in/yaml.v2"
 )
@@ -24,6 +25,28 @@ func ReadImagesFile(imagesFile string) (map[string]string, error) {
 	return containerImages, nil
 }
 
+const imageOverrideEnvPrefix = "RELATED_IMAGE_"
+
+// ReadImageOverrides reads provider image overrides from RELATED_IMAGE_<PROVIDER> environment variables
+// and returns them keyed by provider name, e.g. RELATED_IMAGE_CLUSTER_API overrides the cluster-api provider image.
+func ReadImageOverrides(environ []string) map[string]string {
+	overrides := map[string]string{}
+	for _, env := range environ {
+		key, value, found := strings.Cut(env, "=")
+		if !found || !strings.HasPrefix(key, imageOverrideEnvPrefix) || value == "" {
+			continue
+		}
+
+		name := strings.TrimPrefix(key, imageOverrideEnvPrefix)
+		if name == "" {
+			continue
+		}
+		overrides[strings.ReplaceAll(strings.ToLower(name), "_", "-")] = value
+	}
+
+	return overrides
+}
+
 type provider struct {
 	Name string `json:"name"`
 }
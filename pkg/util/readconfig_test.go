@@ -0,0 +1,76 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Read image overrides", func() {
+	It("should return overrides keyed by provider name", func() {
+		overrides := ReadImageOverrides([]string{
+			"RELATED_IMAGE_CLUSTER_API=quay.io/foo/cluster-api:latest",
+			"RELATED_IMAGE_AWS=quay.io/foo/cluster-api-provider-aws@sha256:abc",
+			"RELATED_IMAGE_KUBE_RBAC_PROXY=quay.io/foo/kube-rbac-proxy:latest",
+		})
+		Expect(overrides).To(Equal(map[string]string{
+			"cluster-api":     "quay.io/foo/cluster-api:latest",
+			"aws":             "quay.io/foo/cluster-api-provider-aws@sha256:abc",
+			"kube-rbac-proxy": "quay.io/foo/kube-rbac-proxy:latest",
+		}))
+	})
+
+	It("should ignore unrelated and empty variables", func() {
+		overrides := ReadImageOverrides([]string{
+			"RELEASE_VERSION=4.14.0",
+			"RELATED_IMAGE_GCP=",
+			"RELATED_IMAGE_=quay.io/foo/bar:latest",
+			"HOME",
+		})
+		Expect(overrides).To(BeEmpty())
+	})
+})
+
+var _ = Describe("Read providers file", func() {
+	var providersFile string
+
+	BeforeEach(func() {
+		providersFile = filepath.Join(GinkgoT().TempDir(), "providers-list.yaml")
+	})
+
+	It("should return the listed infrastructure providers", func() {
+		Expect(os.WriteFile(providersFile, []byte(`- name: cluster-api
+  type: CoreProvider
+- name: aws
+  type: InfrastructureProvider
+- name: powervs
+  type: InfrastructureProvider
+- name: ibmcloud
+  type: InfrastructureProvider
+`), 0600)).To(Succeed())
+
+		providers, err := ReadProvidersFile(providersFile)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(providers).To(Equal(map[string]bool{
+			"aws":      true,
+			"powervs":  true,
+			"ibmcloud": true,
+		}))
+	})
+
+	It("should not enable the IBMCloud platform along with Power VS", func() {
+		Expect(os.WriteFile(providersFile, []byte(`- name: cluster-api
+  type: CoreProvider
+- name: powervs
+  type: InfrastructureProvider
+`), 0600)).To(Succeed())
+
+		providers, err := ReadProvidersFile(providersFile)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(providers).To(Equal(map[string]bool{
+			"powervs": true,
+		}))
+	})
+})
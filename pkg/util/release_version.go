@@ -0,0 +1,52 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+const (
+	// ReleaseVersionEnvVariableName is the environment variable holding the release version of the operator.
+	ReleaseVersionEnvVariableName = "RELEASE_VERSION"
+
+	clusterVersionResourceName = "version"
+)
+
+// GetReleaseVersion returns the release version set in the RELEASE_VERSION environment variable.
+// When the variable is not set, the desired version of the ClusterVersion object is used instead,
+// and missingVersion is returned when that can not be read either.
+func GetReleaseVersion(ctx context.Context, cl client.Reader, missingVersion string) string {
+	log := ctrl.LoggerFrom(ctx)
+
+	if releaseVersion := os.Getenv(ReleaseVersionEnvVariableName); releaseVersion != "" {
+		return releaseVersion
+	}
+
+	releaseVersion, err := clusterVersionDesiredVersion(ctx, cl)
+	if err != nil {
+		log.Info(fmt.Sprintf("%s environment variable is missing and the ClusterVersion can not be read, defaulting to %q", ReleaseVersionEnvVariableName, missingVersion), "reason", err.Error())
+		return missingVersion
+	}
+
+	log.Info(fmt.Sprintf("%s environment variable is missing, using the desired version of the ClusterVersion", ReleaseVersionEnvVariableName), "version", releaseVersion)
+	return releaseVersion
+}
+
+func clusterVersionDesiredVersion(ctx context.Context, cl client.Reader) (string, error) {
+	clusterVersion := &configv1.ClusterVersion{}
+	if err := cl.Get(ctx, client.ObjectKey{Name: clusterVersionResourceName}, clusterVersion); err != nil {
+		return "", fmt.Errorf("unable to get ClusterVersion: %v", err)
+	}
+
+	if clusterVersion.Status.Desired.Version == "" {
+		return "", fmt.Errorf("ClusterVersion has no desired version")
+	}
+
+	return clusterVersion.Status.Desired.Version, nil
+}
@@ -0,0 +1,61 @@
+package util
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+var _ = Describe("Get release version", func() {
+	const missingVersion = "0.0.1-snapshot"
+
+	var scheme *runtime.Scheme
+	var clusterVersion *configv1.ClusterVersion
+
+	BeforeEach(func() {
+		scheme = runtime.NewScheme()
+		Expect(configv1.AddToScheme(scheme)).To(Succeed())
+
+		clusterVersion = &configv1.ClusterVersion{
+			ObjectMeta: metav1.ObjectMeta{Name: "version"},
+			Status: configv1.ClusterVersionStatus{
+				Desired: configv1.Release{Version: "4.13.0"},
+			},
+		}
+	})
+
+	It("should use the environment variable when it is set", func() {
+		GinkgoT().Setenv(ReleaseVersionEnvVariableName, "4.14.0")
+		cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(clusterVersion).Build()
+
+		Expect(GetReleaseVersion(context.Background(), cl, missingVersion)).To(Equal("4.14.0"))
+	})
+
+	It("should fall back to the desired version of the ClusterVersion when the environment variable is not set", func() {
+		GinkgoT().Setenv(ReleaseVersionEnvVariableName, "")
+		cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(clusterVersion).Build()
+
+		Expect(GetReleaseVersion(context.Background(), cl, missingVersion)).To(Equal("4.13.0"))
+	})
+
+	It("should use the missing version when the ClusterVersion has no desired version", func() {
+		GinkgoT().Setenv(ReleaseVersionEnvVariableName, "")
+		clusterVersion.Status.Desired.Version = ""
+		cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(clusterVersion).Build()
+
+		Expect(GetReleaseVersion(context.Background(), cl, missingVersion)).To(Equal(missingVersion))
+	})
+
+	It("should use the missing version when neither the environment variable nor the ClusterVersion exist", func() {
+		GinkgoT().Setenv(ReleaseVersionEnvVariableName, "")
+		cl := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+		Expect(GetReleaseVersion(context.Background(), cl, missingVersion)).To(Equal(missingVersion))
+	})
+})
@@ -0,0 +1,89 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// informerGetter is the subset of the cache used to get the informer of an object type.
+type informerGetter interface {
+	GetInformer(ctx context.Context, obj client.Object) (cache.Informer, error)
+}
+
+// InformerWatchdog tracks when informers last delivered an event and fails its health check once one of them
+// has been quiet for longer than the window. Resyncs are delivered as update events, so an informer holding
+// at least one object delivers an event every sync period unless its watch is broken.
+type InformerWatchdog struct {
+	window time.Duration
+	now    func() time.Time
+
+	mu        sync.Mutex
+	lastEvent map[string]time.Time
+}
+
+// NewInformerWatchdog returns a watchdog failing once an informer has not delivered an event for longer than window.
+func NewInformerWatchdog(window time.Duration) *InformerWatchdog {
+	return &InformerWatchdog{
+		window:    window,
+		now:       time.Now,
+		lastEvent: map[string]time.Time{},
+	}
+}
+
+// Watch starts tracking the informers of the given object types. Only types with objects that always exist,
+// such as cluster singletons, should be watched, as an empty informer never delivers resync events.
+func (w *InformerWatchdog) Watch(ctx context.Context, informers informerGetter, objs ...client.Object) error {
+	for _, obj := range objs {
+		informer, err := informers.GetInformer(ctx, obj)
+		if err != nil {
+			return fmt.Errorf("unable to get informer for %T: %v", obj, err)
+		}
+
+		name := fmt.Sprintf("%T", obj)
+		w.observe(name)
+		informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+			AddFunc:    func(interface{}) { w.observe(name) },
+			UpdateFunc: func(interface{}, interface{}) { w.observe(name) },
+			DeleteFunc: func(interface{}) { w.observe(name) },
+		})
+	}
+
+	return nil
+}
+
+// Check implements healthz.Checker.
+func (w *InformerWatchdog) Check(_ *http.Request) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := w.now()
+	stale := []string{}
+	for name, last := range w.lastEvent {
+		if now.Sub(last) > w.window {
+			stale = append(stale, name)
+		}
+	}
+
+	if len(stale) > 0 {
+		sort.Strings(stale)
+		return fmt.Errorf("informers have not delivered an event for more than %v: %s", w.window, strings.Join(stale, ", "))
+	}
+
+	return nil
+}
+
+func (w *InformerWatchdog) observe(name string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.lastEvent[name] = w.now()
+}
@@ -0,0 +1,60 @@
+package util
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+type fakeInformer struct {
+	cache.Informer
+	handler toolscache.ResourceEventHandler
+}
+
+func (f *fakeInformer) AddEventHandler(handler toolscache.ResourceEventHandler) {
+	f.handler = handler
+}
+
+type fakeInformerGetter struct {
+	informer *fakeInformer
+}
+
+func (f *fakeInformerGetter) GetInformer(_ context.Context, _ client.Object) (cache.Informer, error) {
+	return f.informer, nil
+}
+
+var _ = Describe("Informer watchdog", func() {
+	var watchdog *InformerWatchdog
+	var informer *fakeInformer
+	var now time.Time
+
+	BeforeEach(func() {
+		now = time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+		watchdog = NewInformerWatchdog(30 * time.Minute)
+		watchdog.now = func() time.Time { return now }
+
+		informer = &fakeInformer{}
+		Expect(watchdog.Watch(context.Background(), &fakeInformerGetter{informer: informer}, &configv1.Infrastructure{})).To(Succeed())
+	})
+
+	It("should pass while the informer delivers events within the window", func() {
+		now = now.Add(20 * time.Minute)
+		Expect(watchdog.Check(nil)).To(Succeed())
+
+		informer.handler.OnUpdate(nil, nil)
+		now = now.Add(20 * time.Minute)
+		Expect(watchdog.Check(nil)).To(Succeed())
+	})
+
+	It("should fail once the informer has been quiet for longer than the window", func() {
+		now = now.Add(31 * time.Minute)
+		Expect(watchdog.Check(nil)).To(MatchError(ContainSubstring("*v1.Infrastructure")))
+	})
+})
@@ -5,13 +5,22 @@ import (
 	"errors"
 	"fmt"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/cluster-api/api/v1beta1"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/cluster-capi-operator/pkg/controllers"
 )
 
 type ClusterWebhook struct {
+	// Client is used to look up the cluster Infrastructure when a Cluster is deleted.
+	Client client.Reader
+	// ManagedNamespace is the namespace of the Cluster managed by the operator.
+	ManagedNamespace string
 }
 
 func (r *ClusterWebhook) SetupWebhookWithManager(mgr ctrl.Manager) error {
@@ -30,7 +39,7 @@ func (r *ClusterWebhook) ValidateCreate(ctx context.Context, obj runtime.Object)
 		panic("expected to get an of object of type v1beta1.Cluster")
 	}
 	switch cluster.Spec.InfrastructureRef.Kind {
-	case "AWSCluster", "AzureCluster", "GCPCluster", "IBMPowerVSCluster":
+	case "AWSCluster", "AzureCluster", "GCPCluster", "IBMPowerVSCluster", "IBMVPCCluster":
 	default:
 		return fmt.Errorf("unsupported cluster infra provider kind: %s", cluster.Spec.InfrastructureRef.Kind)
 	}
@@ -50,7 +59,7 @@ func (r *ClusterWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runt
 	}
 
 	switch newCluster.Spec.InfrastructureRef.Kind {
-	case "AWSCluster", "AzureCluster", "GCPCluster", "IBMPowerVSCluster":
+	case "AWSCluster", "AzureCluster", "GCPCluster", "IBMPowerVSCluster", "IBMVPCCluster":
 	default:
 		return fmt.Errorf("unsupported cluster infra provider kind: %s", newCluster.Spec.InfrastructureRef.Kind)
 	}
@@ -59,6 +68,27 @@ func (r *ClusterWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runt
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
-func (r *ClusterWebhook) ValidateDelete(_ context.Context, obj runtime.Object) error {
-	return errors.New("deletion of cluster is not allowed")
+func (r *ClusterWebhook) ValidateDelete(ctx context.Context, obj runtime.Object) error {
+	cluster, ok := obj.(*v1beta1.Cluster)
+	if !ok {
+		panic("expected to get an of object of type v1beta1.Cluster")
+	}
+
+	if cluster.Namespace != r.ManagedNamespace {
+		return nil
+	}
+
+	infra := &configv1.Infrastructure{}
+	if err := r.Client.Get(ctx, client.ObjectKey{Name: controllers.InfrastructureResourceName}, infra); apierrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("unable to get cluster infrastructure: %v", err)
+	}
+
+	// The Cluster named after the infrastructure name represents the cluster the operator runs on
+	if cluster.Name == infra.Status.InfrastructureName {
+		return errors.New("deletion of cluster is not allowed while the cluster infrastructure exists")
+	}
+
+	return nil
 }
@@ -0,0 +1,68 @@
+package webhook
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/openshift/cluster-capi-operator/pkg/controllers"
+)
+
+var _ = Describe("Cluster webhook", func() {
+	var scheme *runtime.Scheme
+	var infra *configv1.Infrastructure
+	ctx := context.Background()
+
+	BeforeEach(func() {
+		scheme = runtime.NewScheme()
+		utilruntime.Must(configv1.Install(scheme))
+
+		infra = &configv1.Infrastructure{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: controllers.InfrastructureResourceName,
+			},
+			Status: configv1.InfrastructureStatus{
+				InfrastructureName: "test-infra-name",
+			},
+		}
+	})
+
+	cluster := func(name, namespace string) *v1beta1.Cluster {
+		return &v1beta1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	}
+
+	It("should reject deletion of the managed cluster while the infrastructure exists", func() {
+		r := &ClusterWebhook{
+			Client:           fake.NewClientBuilder().WithScheme(scheme).WithObjects(infra).Build(),
+			ManagedNamespace: controllers.DefaultManagedNamespace,
+		}
+
+		Expect(r.ValidateDelete(ctx, cluster("test-infra-name", controllers.DefaultManagedNamespace))).To(MatchError(ContainSubstring("deletion of cluster is not allowed")))
+	})
+
+	It("should allow deletion of the managed cluster once the infrastructure is gone", func() {
+		r := &ClusterWebhook{
+			Client:           fake.NewClientBuilder().WithScheme(scheme).Build(),
+			ManagedNamespace: controllers.DefaultManagedNamespace,
+		}
+
+		Expect(r.ValidateDelete(ctx, cluster("test-infra-name", controllers.DefaultManagedNamespace))).To(Succeed())
+	})
+
+	It("should allow deletion of other clusters", func() {
+		r := &ClusterWebhook{
+			Client:           fake.NewClientBuilder().WithScheme(scheme).WithObjects(infra).Build(),
+			ManagedNamespace: controllers.DefaultManagedNamespace,
+		}
+
+		Expect(r.ValidateDelete(ctx, cluster("other", controllers.DefaultManagedNamespace))).To(Succeed())
+		Expect(r.ValidateDelete(ctx, cluster("test-infra-name", "other-namespace"))).To(Succeed())
+	})
+})
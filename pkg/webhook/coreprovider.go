@@ -12,6 +12,8 @@ import (
 )
 
 type CoreProviderWebhook struct {
+	// OperatorUsername is the user of the operator, which is allowed to prune the core provider.
+	OperatorUsername string
 }
 
 func (r *CoreProviderWebhook) SetupWebhookWithManager(mgr ctrl.Manager) error {
@@ -56,6 +58,11 @@ func (r *CoreProviderWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
-func (r *CoreProviderWebhook) ValidateDelete(_ context.Context, obj runtime.Object) error {
+func (r *CoreProviderWebhook) ValidateDelete(ctx context.Context, obj runtime.Object) error {
+	// The operator prunes providers that are no longer part of its assets
+	if isOperatorRequest(ctx, r.OperatorUsername) {
+		return nil
+	}
+
 	return errors.New("deletion of core provider is not allowed")
 }
@@ -15,6 +15,8 @@ import (
 
 type InfrastructureProviderWebhook struct {
 	Platform configv1.PlatformType
+	// OperatorUsername is the user of the operator, which is allowed to prune infrastructure providers.
+	OperatorUsername string
 }
 
 func (r *InfrastructureProviderWebhook) SetupWebhookWithManager(mgr ctrl.Manager) error {
@@ -52,6 +54,10 @@ func (r *InfrastructureProviderWebhook) ValidateCreate(ctx context.Context, obj
 		if infraProvider.Name != "ibmcloud" {
 			return fmt.Errorf("incorrect infra provider name for PowerVS platform: %s", infraProvider.Name)
 		}
+	case configv1.IBMCloudPlatformType:
+		if infraProvider.Name != "ibmcloud" {
+			return fmt.Errorf("incorrect infra provider name for IBMCloud platform: %s", infraProvider.Name)
+		}
 	default:
 		return errors.New("platform not supported, skipping infra cluster controller setup")
 	}
@@ -89,6 +95,10 @@ func (r *InfrastructureProviderWebhook) ValidateUpdate(ctx context.Context, oldO
 		if newInfraProvider.Name != "ibmcloud" {
 			return fmt.Errorf("incorrect infra provider name for PowerVS platform: %s", newInfraProvider.Name)
 		}
+	case configv1.IBMCloudPlatformType:
+		if newInfraProvider.Name != "ibmcloud" {
+			return fmt.Errorf("incorrect infra provider name for IBMCloud platform: %s", newInfraProvider.Name)
+		}
 	default:
 		return errors.New("platform not supported, skipping infra cluster controller setup")
 	}
@@ -97,6 +107,11 @@ func (r *InfrastructureProviderWebhook) ValidateUpdate(ctx context.Context, oldO
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
-func (r *InfrastructureProviderWebhook) ValidateDelete(_ context.Context, obj runtime.Object) error {
+func (r *InfrastructureProviderWebhook) ValidateDelete(ctx context.Context, obj runtime.Object) error {
+	// The operator prunes providers that are no longer part of its assets
+	if isOperatorRequest(ctx, r.OperatorUsername) {
+		return nil
+	}
+
 	return errors.New("deletion of infrastructure provider is not allowed")
 }
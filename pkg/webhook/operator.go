@@ -0,0 +1,27 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// ServiceAccountUsername returns the username the given service account authenticates with.
+func ServiceAccountUsername(namespace, name string) string {
+	return fmt.Sprintf("system:serviceaccount:%s:%s", namespace, name)
+}
+
+// isOperatorRequest returns true when the admission request was made by the given operator user.
+func isOperatorRequest(ctx context.Context, operatorUsername string) bool {
+	if operatorUsername == "" {
+		return false
+	}
+
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return false
+	}
+
+	return req.UserInfo.Username == operatorUsername
+}
@@ -0,0 +1,72 @@
+package webhook
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/cluster-api-operator/api/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+var _ = Describe("Provider deletion", func() {
+	operatorUsername := ServiceAccountUsername("openshift-cluster-api", "cluster-capi-operator")
+
+	requestBy := func(username string) context.Context {
+		return admission.NewContextWithRequest(context.Background(), admission.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{
+				Operation: admissionv1.Delete,
+				UserInfo:  authenticationv1.UserInfo{Username: username},
+			},
+		})
+	}
+
+	coreProvider := &v1alpha1.CoreProvider{ObjectMeta: metav1.ObjectMeta{Name: "cluster-api"}}
+	infraProvider := &v1alpha1.InfrastructureProvider{ObjectMeta: metav1.ObjectMeta{Name: "aws"}}
+
+	It("should build the service account username", func() {
+		Expect(operatorUsername).To(Equal("system:serviceaccount:openshift-cluster-api:cluster-capi-operator"))
+	})
+
+	Context("core provider", func() {
+		r := &CoreProviderWebhook{OperatorUsername: operatorUsername}
+
+		It("should allow the operator to prune it", func() {
+			Expect(r.ValidateDelete(requestBy(operatorUsername), coreProvider)).To(Succeed())
+		})
+
+		It("should reject deletion by another user", func() {
+			Expect(r.ValidateDelete(requestBy("kube:admin"), coreProvider)).To(MatchError("deletion of core provider is not allowed"))
+		})
+
+		It("should reject deletion by the operator service account of another namespace", func() {
+			Expect(r.ValidateDelete(requestBy(ServiceAccountUsername("other", "cluster-capi-operator")), coreProvider)).To(HaveOccurred())
+		})
+
+		It("should reject deletion without an admission request", func() {
+			Expect(r.ValidateDelete(context.Background(), coreProvider)).To(HaveOccurred())
+		})
+	})
+
+	Context("infrastructure provider", func() {
+		r := &InfrastructureProviderWebhook{Platform: configv1.AWSPlatformType, OperatorUsername: operatorUsername}
+
+		It("should allow the operator to prune it", func() {
+			Expect(r.ValidateDelete(requestBy(operatorUsername), infraProvider)).To(Succeed())
+		})
+
+		It("should reject deletion by another user", func() {
+			Expect(r.ValidateDelete(requestBy("kube:admin"), infraProvider)).To(MatchError("deletion of infrastructure provider is not allowed"))
+		})
+
+		It("should reject deletion when the operator user is not configured", func() {
+			unconfigured := &InfrastructureProviderWebhook{Platform: configv1.AWSPlatformType}
+			Expect(unconfigured.ValidateDelete(requestBy(""), infraProvider)).To(HaveOccurred())
+		})
+	})
+})